@@ -0,0 +1,250 @@
+package golden
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Expect declares the names of golden files which the given TestingT instance
+// is expected to use. When the test finishes, any of them which were not used
+// are reported.
+//
+// This is a wrapper around calling Expect() on the Default *Golden instance.
+func Expect(t TestingT, names ...string) {
+	t.Helper()
+
+	Default.Expect(t, names...)
+}
+
+// Expect declares the names of golden files which the given TestingT instance
+// is expected to use, with DoP(), AssertP(), or any other "P" suffixed method.
+// When the test finishes, each named golden file which was not used is
+// reported with t.Errorf(), for example:
+//
+//	golden: testdata/TestFoo/xml.golden was expected but never used
+//
+// This helps catch golden files which are no longer checked, as tabular tests
+// change over time. It requires t to implement CleanupT, otherwise the test is
+// failed according to FailMode.
+func (s *Golden) Expect(t TestingT, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		if name == "" {
+			s.fail(t, "golden: name cannot be empty")
+
+			return
+		}
+	}
+
+	st := s.testState(t)
+	if st == nil {
+		return
+	}
+
+	for _, name := range names {
+		f, err := s.resolve(t, name)
+		if err != nil {
+			s.fail(t, "%s", err.Error())
+
+			return
+		}
+
+		st.expect(s.label(f, name))
+	}
+}
+
+// testState holds the end-of-test work registered for a TestingT instance.
+type testState struct {
+	mu       sync.Mutex
+	flushers []func()
+	reads    []string
+	compared map[string]bool
+	expected []string
+	used     map[string]bool
+}
+
+// testStates holds the testState of every running test which has registered
+// end-of-test work, keyed by its TestingT instance.
+var testStates = struct {
+	sync.Mutex
+	m map[TestingT]*testState
+}{m: map[TestingT]*testState{}}
+
+// activeTestStates is the number of entries in testStates, allowing lookups to
+// be skipped entirely when no test has registered end-of-test work.
+var activeTestStates int32
+
+// lookupTestState returns the testState of t, or nil if there is none.
+func lookupTestState(t TestingT) *testState {
+	if atomic.LoadInt32(&activeTestStates) == 0 || !isComparable(t) {
+		return nil
+	}
+
+	testStates.Lock()
+	defer testStates.Unlock()
+
+	return testStates.m[t]
+}
+
+// isComparable returns true if t can be used as a map key.
+func isComparable(t TestingT) bool {
+	return t != nil && reflect.TypeOf(t).Comparable()
+}
+
+// testState returns the testState of t, creating it and registering its
+// end-of-test work with t.Cleanup() if needed. If t does not implement
+// CleanupT, or cannot be used as a map key, the test is failed according to
+// FailMode, and nil is returned.
+func (s *Golden) testState(t TestingT) *testState {
+	t.Helper()
+
+	ct, ok := t.(CleanupT)
+	if !ok {
+		s.fail(t, "golden: %T does not support Cleanup()", t)
+
+		return nil
+	}
+	if !isComparable(t) {
+		s.fail(t, "golden: %T is not comparable", t)
+
+		return nil
+	}
+
+	testStates.Lock()
+	defer testStates.Unlock()
+
+	if st, ok := testStates.m[t]; ok {
+		return st
+	}
+
+	st := &testState{
+		compared: map[string]bool{},
+		used:     map[string]bool{},
+	}
+	testStates.m[t] = st
+	atomic.AddInt32(&activeTestStates, 1)
+
+	ct.Cleanup(func() {
+		t.Helper()
+
+		defer func() {
+			testStates.Lock()
+			delete(testStates.m, t)
+			testStates.Unlock()
+			atomic.AddInt32(&activeTestStates, -1)
+		}()
+
+		st.finish(t)
+	})
+
+	return st
+}
+
+// finish flushes all writers, and then reports golden files which were read
+// but never compared, and expected golden files which were never used.
+func (st *testState) finish(t TestingT) {
+	t.Helper()
+
+	st.mu.Lock()
+	flushers := st.flushers
+	st.flushers = nil
+	st.mu.Unlock()
+
+	for _, fn := range flushers {
+		fn()
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, f := range st.reads {
+		if !st.compared[f] {
+			t.Errorf("golden: %s was read but never compared", f)
+		}
+	}
+
+	for _, f := range st.expected {
+		if !st.used[f] {
+			t.Errorf("golden: %s was expected but never used", f)
+		}
+	}
+}
+
+// onFinish registers fn to be called when the test finishes, before any
+// verification is performed.
+func (st *testState) onFinish(fn func()) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.flushers = append(st.flushers, fn)
+}
+
+func (st *testState) read(file string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, f := range st.reads {
+		if f == file {
+			return
+		}
+	}
+	st.reads = append(st.reads, file)
+}
+
+func (st *testState) compare(file string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.compared[file] = true
+}
+
+func (st *testState) expect(file string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.expected = append(st.expected, file)
+}
+
+func (st *testState) use(file string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.used[file] = true
+}
+
+// trackRead records that the named golden file was read by the given TestingT
+// instance, if VerifyGets is enabled.
+func (s *Golden) trackRead(t TestingT, name string) {
+	t.Helper()
+
+	if !s.VerifyGets {
+		return
+	}
+
+	f, err := s.resolve(t, name)
+	if err != nil {
+		return
+	}
+
+	if st := s.testState(t); st != nil {
+		st.read(s.label(f, name))
+	}
+}
+
+// trackCompare records that the golden file with the given label was compared
+// by the given TestingT instance.
+func trackCompare(t TestingT, label string) {
+	if st := lookupTestState(t); st != nil {
+		st.compare(label)
+	}
+}
+
+// trackUse records that the golden file with the given label was used by the
+// given TestingT instance.
+func trackUse(t TestingT, label string) {
+	if st := lookupTestState(t); st != nil {
+		st.use(label)
+	}
+}
@@ -0,0 +1,274 @@
+package golden
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGolden_VerifyGets(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		verify     bool
+		fn         func(g *Golden, ft TestingT)
+		wantErrors []string
+	}{
+		{
+			name:   "get without compare",
+			verify: true,
+			fn: func(g *Golden, ft TestingT) {
+				g.Get(ft)
+				g.GetP(ft, "a")
+			},
+			wantErrors: []string{
+				"get_without_compare.golden was read but never compared",
+				filepath.Join("get_without_compare", "a.golden") +
+					" was read but never compared",
+			},
+		},
+		{
+			name:   "do without compare",
+			verify: true,
+			fn: func(g *Golden, ft TestingT) {
+				g.Do(ft, []byte("foo"))
+				g.DoP(ft, "a", []byte("foo"))
+				g.DoP(ft, "a", []byte("foo"))
+			},
+			wantErrors: []string{
+				"do_without_compare.golden was read but never compared",
+				filepath.Join("do_without_compare", "a.golden") +
+					" was read but never compared",
+			},
+		},
+		{
+			name:   "get and compare",
+			verify: true,
+			fn: func(g *Golden, ft TestingT) {
+				g.Get(ft)
+				g.Assert(ft, []byte("foo"))
+				g.GetP(ft, "a")
+				g.AssertP(ft, "a", []byte("foo"))
+				g.DoP(ft, "b", []byte("foo"))
+				g.AssertP(ft, "b", []byte("foo"))
+			},
+		},
+		{
+			name:   "writer flushed before verification",
+			verify: true,
+			fn: func(g *Golden, ft TestingT) {
+				g.GetP(ft, "a")
+				fmt.Fprint(g.WriterP(ft, "a"), "foo")
+			},
+		},
+		{
+			name: "disabled",
+			fn: func(g *Golden, ft TestingT) {
+				g.Get(ft)
+				g.GetP(ft, "a")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return false }),
+				WithVerifyGets(tt.verify),
+			)
+			ft := newFakeCleanupT("TestVerifyGets/" + tt.name)
+
+			err := g.Write(ft, []byte("foo"))
+			require.NoError(t, err)
+			for _, name := range []string{"a", "b"} {
+				err = g.WriteP(ft, name, []byte("foo"))
+				require.NoError(t, err)
+			}
+
+			tt.fn(g, ft)
+			assert.False(t, ft.Failed(), "verified before cleanup")
+
+			ft.cleanup()
+
+			assert.Len(t, ft.errors, len(tt.wantErrors), ft.Output())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+			assert.Nil(t, lookupTestState(ft))
+		})
+	}
+}
+
+func TestGolden_VerifyGets_NoCleanup(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(WithDirname(t.TempDir()), WithVerifyGets(true))
+	ft := newFakeT("TestVerifyGets_NoCleanup")
+
+	err := g.Write(ft, []byte("foo"))
+	require.NoError(t, err)
+
+	ft.run(func(ft TestingT) { g.Get(ft) })
+
+	assert.Equal(t,
+		[]string{"golden: *golden.fakeT does not support Cleanup()"},
+		ft.fatals,
+	)
+}
+
+func TestGolden_Expect(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		storage    Storage
+		expect     []string
+		use        []string
+		wantErrors []string
+	}{
+		{
+			name:   "all used",
+			expect: []string{"json", "xml"},
+			use:    []string{"xml", "json", "yaml"},
+		},
+		{
+			name:   "unused",
+			expect: []string{"json", "xml", "yaml"},
+			use:    []string{"json"},
+			wantErrors: []string{
+				filepath.Join("unused", "xml.golden") +
+					" was expected but never used",
+				filepath.Join("unused", "yaml.golden") +
+					" was expected but never used",
+			},
+		},
+		{
+			name:    "unused txtar",
+			storage: StorageTxtar,
+			expect:  []string{"json", "xml"},
+			use:     []string{"json"},
+			wantErrors: []string{
+				"unused_txtar.txtar#xml was expected but never used",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return true }),
+				WithStorage(tt.storage),
+			)
+			ft := newFakeCleanupT("TestExpect/" + tt.name)
+
+			g.Expect(ft, tt.expect...)
+			for _, name := range tt.use {
+				g.DoP(ft, name, []byte("foo"))
+			}
+
+			ft.cleanup()
+
+			assert.Len(t, ft.errors, len(tt.wantErrors), ft.Output())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+			assert.Nil(t, lookupTestState(ft))
+		})
+	}
+}
+
+func TestGolden_Expect_Errors(t *testing.T) {
+	g := New(WithDirname(t.TempDir()))
+
+	t.Run("no cleanup support", func(t *testing.T) {
+		ft := newFakeT("TestExpect_Errors")
+
+		ft.run(func(ft TestingT) { g.Expect(ft, "json") })
+
+		assert.Equal(t,
+			[]string{"golden: *golden.fakeT does not support Cleanup()"},
+			ft.fatals,
+		)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		ft := newFakeCleanupT("TestExpect_Errors")
+
+		ft.run(func(TestingT) { g.Expect(ft, "json", "") })
+
+		assert.Equal(t, []string{"golden: name cannot be empty"}, ft.fatals)
+		assert.Empty(t, ft.cleanups)
+	})
+
+	t.Run("no test name", func(t *testing.T) {
+		ft := newFakeCleanupT("")
+
+		ft.run(func(TestingT) { g.Expect(ft, "json") })
+
+		assert.Equal(t,
+			[]string{"golden: could not determine filename"}, ft.fatals,
+		)
+		ft.cleanup()
+		assert.Nil(t, lookupTestState(ft))
+	})
+}
+
+func TestExpect(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+	ft := newFakeCleanupT("TestExpect")
+
+	Expect(ft, "json", "xml")
+	DoP(ft, "json", []byte("{}"))
+	ft.cleanup()
+
+	assert.Equal(t,
+		[]string{
+			"golden: " + FileP(ft, "xml") + " was expected but never used",
+		},
+		ft.errors,
+	)
+}
+
+// mapT is a TestingT and CleanupT implementation which is not comparable, and
+// hence cannot be used as a map key.
+type mapT struct {
+	*fakeCleanupT
+
+	m map[string]int
+}
+
+func TestGolden_NonComparableT(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	)
+
+	// Ensure lookups are not skipped due to no test state being active.
+	active := newFakeCleanupT("TestNonComparableT/active")
+	g.Expect(active, "json")
+	defer active.cleanup()
+
+	mt := mapT{fakeCleanupT: newFakeCleanupT("TestNonComparableT")}
+
+	assert.NotPanics(t, func() {
+		g.File(mt)
+		assert.True(t, g.AssertP(mt, "json", []byte("{}")))
+	})
+	assert.False(t, mt.Failed(), mt.Output())
+
+	mt.run(func(TestingT) { g.Expect(mt, "json") })
+	assert.Equal(t,
+		[]string{"golden: golden.mapT is not comparable"}, mt.fatals,
+	)
+}
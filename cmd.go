@@ -0,0 +1,76 @@
+package golden
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+// DoCmd runs cmd, and compares its stdout, stderr, and exit code against
+// separate golden files. Returns true if all of them match.
+//
+// This is a wrapper around calling DoCmd() on the Default *Golden instance.
+func DoCmd(t TestingT, cmd *exec.Cmd) bool {
+	t.Helper()
+
+	return Default.DoCmd(t, cmd)
+}
+
+// DoCmd runs cmd, capturing its stdout, stderr, and exit code. Each of them is
+// then handled just like AssertP() does, with the names "stdout", "stderr",
+// and "exitcode" respectively, for example:
+//
+//	testdata/TestCLI/stdout.golden
+//	testdata/TestCLI/stderr.golden
+//	testdata/TestCLI/exitcode.golden
+//
+// Scrubbers and Normalization are applied to the captured output, and each
+// stream which does not match is reported with its own diff. If cmd.Stdout or
+// cmd.Stderr are already set, output is written to them as well.
+//
+// A non-zero exit code is not considered a failure, as it is compared against
+// the golden file like the output. If cmd cannot be run at all, the test is
+// failed according to FailMode.
+func (s *Golden) DoCmd(t TestingT, cmd *exec.Cmd) bool {
+	t.Helper()
+
+	if cmd == nil {
+		s.fail(t, "golden: command cannot be nil")
+
+		return false
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = teeWriter(cmd.Stdout, &stdout)
+	cmd.Stderr = teeWriter(cmd.Stderr, &stderr)
+
+	code := 0
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		s.fail(t, "golden: failed to run command: %s", err.Error())
+
+		return false
+	}
+
+	ok := s.assert(t, "stdout", stdout.Bytes())
+	ok = s.assert(t, "stderr", stderr.Bytes()) && ok
+	ok = s.assert(t, "exitcode", []byte(strconv.Itoa(code)+"\n")) && ok
+
+	return ok
+}
+
+// teeWriter returns buf, or a writer duplicating writes to both w and buf if
+// w is not nil.
+func teeWriter(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+
+	return io.MultiWriter(w, buf)
+}
@@ -0,0 +1,197 @@
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperCommand returns a command running TestHelperProcess, which writes
+// stdout and stderr, and exits with code.
+func helperCommand(stdout, stderr string, code int) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(),
+		"GO_GOLDEN_HELPER_PROCESS=1",
+		"GO_GOLDEN_HELPER_STDOUT="+stdout,
+		"GO_GOLDEN_HELPER_STDERR="+stderr,
+		"GO_GOLDEN_HELPER_CODE="+strconv.Itoa(code),
+	)
+
+	return cmd
+}
+
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_GOLDEN_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	fmt.Fprint(os.Stdout, os.Getenv("GO_GOLDEN_HELPER_STDOUT"))
+	fmt.Fprint(os.Stderr, os.Getenv("GO_GOLDEN_HELPER_STDERR"))
+	code, _ := strconv.Atoi(os.Getenv("GO_GOLDEN_HELPER_CODE"))
+	os.Exit(code)
+}
+
+func TestGolden_DoCmd(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     map[string]string
+		cmd        *exec.Cmd
+		want       bool
+		wantGolden map[string]string
+		wantErrors []string
+	}{
+		{
+			name: "match",
+			golden: map[string]string{
+				"stdout": "out\n", "stderr": "err\n", "exitcode": "0\n",
+			},
+			cmd:  helperCommand("out\n", "err\n", 0),
+			want: true,
+		},
+		{
+			name: "match non-zero exit code",
+			golden: map[string]string{
+				"stdout": "", "stderr": "failed\n", "exitcode": "3\n",
+			},
+			cmd:  helperCommand("", "failed\n", 3),
+			want: true,
+		},
+		{
+			name:   "update",
+			update: true,
+			golden: map[string]string{"stdout": "old\n"},
+			cmd:    helperCommand("new\n", "", 1),
+			want:   true,
+			wantGolden: map[string]string{
+				"stdout": "new\n", "stderr": "", "exitcode": "1\n",
+			},
+		},
+		{
+			name: "mismatch",
+			golden: map[string]string{
+				"stdout": "out\n", "stderr": "err\n", "exitcode": "0\n",
+			},
+			cmd:  helperCommand("OUT\n", "err\n", 2),
+			want: false,
+			wantErrors: []string{
+				"stdout.golden does not match:\n",
+				"@@ -1 +1 @@\n-out\n+OUT\n",
+				"exitcode.golden does not match:\n",
+				"@@ -1 +1 @@\n-0\n+2\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestDoCmd/" + tt.name)
+
+			for name, content := range tt.golden {
+				err := g.WriteP(ft, name, []byte(content))
+				require.NoError(t, err)
+			}
+
+			got := g.DoCmd(ft, tt.cmd)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed(), ft.Output())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+			assert.NotContains(t, ft.Output(), "stderr.golden does not match")
+
+			for name, want := range tt.wantGolden {
+				b, err := os.ReadFile(g.FileP(ft, name))
+				require.NoError(t, err)
+				assert.Equal(t, want, string(b), name)
+			}
+		})
+	}
+}
+
+func TestGolden_DoCmd_Scrub(t *testing.T) {
+	t.Setenv("CI", "")
+
+	dir := t.TempDir()
+	g := New(
+		WithDirname(dir),
+		WithUpdateFunc(func() bool { return true }),
+		WithScrubbers(UUIDScrubber()),
+	)
+	ft := newFakeT("TestDoCmd_Scrub")
+
+	var stdout bytes.Buffer
+	cmd := helperCommand("id 6ba7b810-9dad-11d1-80b4-00c04fd430c8\n", "", 0)
+	cmd.Stdout = &stdout
+
+	assert.True(t, g.DoCmd(ft, cmd))
+	assert.False(t, ft.Failed(), ft.Output())
+	assert.Equal(t,
+		"id 6ba7b810-9dad-11d1-80b4-00c04fd430c8\n", stdout.String(),
+	)
+
+	b, err := os.ReadFile(
+		filepath.Join(dir, "TestDoCmd_Scrub", "stdout.golden"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "id <UUID-1>\n", string(b))
+}
+
+func TestGolden_DoCmd_Errors(t *testing.T) {
+	g := New(WithDirname(t.TempDir()))
+
+	tests := []struct {
+		name string
+		cmd  *exec.Cmd
+		want string
+	}{
+		{
+			name: "nil command",
+			want: "golden: command cannot be nil",
+		},
+		{
+			name: "command not found",
+			cmd:  exec.Command(filepath.Join(t.TempDir(), "missing")),
+			want: "golden: failed to run command: fork/exec ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeT("TestDoCmd_Errors")
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.DoCmd(ft, tt.cmd) })
+
+			assert.False(t, got)
+			require.Len(t, ft.fatals, 1)
+			assert.Contains(t, ft.fatals[0], tt.want)
+		})
+	}
+}
+
+func TestDoCmd(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+	ft := newFakeT("TestDoCmd")
+
+	assert.True(t, DoCmd(ft, helperCommand("out\n", "", 0)))
+	assert.False(t, ft.Failed(), ft.Output())
+	assert.Equal(t, []byte("out\n"), GetP(ft, "stdout"))
+}
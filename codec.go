@@ -0,0 +1,168 @@
+package golden
+
+import (
+	"path/filepath"
+)
+
+// Codec encodes, normalizes and compares typed values stored in golden files
+// of a specific format. Codecs are registered on a *Golden instance by file
+// extension via its Codecs field, and are used by DoValueP(), DoValueBytes()
+// and AssertValue().
+type Codec interface {
+	// Marshal encodes v into the content of a golden file.
+	Marshal(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes the content of a golden file into v.
+	Unmarshal(data []byte, v interface{}) error
+
+	// Normalize returns the canonical form of data, as it would be written to
+	// a golden file by Marshal.
+	Normalize(data []byte) ([]byte, error)
+
+	// Diff compares the content of a golden file against actual data,
+	// returning a description of their differences. An empty string is
+	// returned when want and got are considered equal.
+	Diff(want, got []byte) string
+}
+
+// CompareCodec is a Codec which supports comparing golden file content with
+// CompareOptions, like a numeric Tolerance. When a codec implements it,
+// AssertValue() uses DiffOptions instead of Diff.
+type CompareCodec interface {
+	Codec
+
+	// DiffOptions compares the content of a golden file against actual data
+	// just like Diff, according to opts.
+	DiffOptions(want, got []byte, opts CompareOptions) string
+}
+
+// AssertValue marshals v with the codec registered for the file extension of
+// name, and then behaves just like AssertValue() on a *Golden instance.
+//
+// This is a wrapper around calling AssertValue() on the Default *Golden
+// instance.
+func AssertValue(t TestingT, name string, v interface{}) bool {
+	t.Helper()
+
+	return Default.AssertValue(t, name, v)
+}
+
+// DoValueBytes marshals v with the codec registered for the file extension of
+// name, and then behaves just like DoValueBytes() on a *Golden instance.
+//
+// This is a wrapper around calling DoValueBytes() on the Default *Golden
+// instance.
+func DoValueBytes(t TestingT, name string, v interface{}) []byte {
+	t.Helper()
+
+	return Default.DoValueBytes(t, name, v)
+}
+
+// DoValueBytes marshals v with the codec registered for the file extension of
+// name, and then behaves just like DoP() with the resulting data. It returns
+// the content of the golden file. To unmarshal it into a typed value, use
+// DoValuePWith() instead.
+//
+// Unlike DoP(), the Suffix is not appended to name, as the file extension of
+// name selects the codec, and is used as is. For example, a name of
+// "resp.json" within TestFoo reads/writes:
+//
+//	testdata/TestFoo/resp.json
+func (s *Golden) DoValueBytes(t TestingT, name string, v interface{}) []byte {
+	t.Helper()
+
+	g, _, data, ok := s.marshalValue(t, name, v)
+	if !ok {
+		return nil
+	}
+
+	b, _ := g.do(t, name, data)
+
+	return b
+}
+
+// AssertValue marshals v with the codec registered for the file extension of
+// name, and then behaves just like AssertP() with the resulting data, except
+// that the golden file content is compared with the Diff method of the codec,
+// or its DiffOptions method if it implements CompareCodec.
+//
+// Just like DoValueBytes(), the Suffix is not appended to name.
+func (s *Golden) AssertValue(t TestingT, name string, v interface{}) bool {
+	t.Helper()
+
+	g, codec, data, ok := s.marshalValue(t, name, v)
+	if !ok {
+		return false
+	}
+
+	return g.assertWith(t, name, data, compareCodec(codec, s.compareOptions()))
+}
+
+// marshalValue marshals v with the codec registered for the file extension of
+// name, and normalizes the result. It also returns the codec, and a copy of s
+// without a Suffix, to be used for reading and writing the golden file.
+func (s *Golden) marshalValue(
+	t TestingT,
+	name string,
+	v interface{},
+) (*Golden, Codec, []byte, bool) {
+	t.Helper()
+
+	if name == "" {
+		s.fail(t, "golden: name cannot be empty")
+
+		return nil, nil, nil, false
+	}
+
+	ext := filepath.Ext(name)
+	codec := s.Codecs[ext]
+	if codec == nil {
+		s.fail(t, "golden: no codec registered for %q file extension", ext)
+
+		return nil, nil, nil, false
+	}
+
+	data, err := codec.Marshal(v)
+	if err == nil {
+		data, err = codec.Normalize(data)
+	}
+	if err != nil {
+		s.fail(t, "golden: failed to marshal %s: %s", name, err.Error())
+
+		return nil, nil, nil, false
+	}
+
+	c := *s
+	c.Suffix = ""
+
+	return &c, codec, data, true
+}
+
+// compareCodec returns a compareFunc which uses the Diff method of codec, or
+// its DiffOptions method with opts if codec is a CompareCodec.
+func compareCodec(codec Codec, opts CompareOptions) compareFunc {
+	return func(_ string, want, got []byte) (string, bool) {
+		var diff string
+		if cc, ok := codec.(CompareCodec); ok {
+			diff = cc.DiffOptions(want, got, opts)
+		} else {
+			diff = codec.Diff(want, got)
+		}
+
+		return diff, diff == ""
+	}
+}
+
+// cloneCodecs returns a shallow copy of the codecs map.
+func cloneCodecs(codecs map[string]Codec) map[string]Codec {
+	if codecs == nil {
+		return nil
+	}
+
+	m := make(map[string]Codec, len(codecs))
+	for ext, c := range codecs {
+		m[ext] = c
+	}
+
+	return m
+}
@@ -0,0 +1,357 @@
+package golden
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperCodec is a Codec which stores strings in upper case, and compares them
+// case-insensitively.
+type upperCodec struct{}
+
+func (c *upperCodec) Marshal(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("not a string")
+	}
+
+	return []byte(s), nil
+}
+
+func (c *upperCodec) Unmarshal(data []byte, v interface{}) error {
+	*(v.(*string)) = string(data)
+
+	return nil
+}
+
+func (c *upperCodec) Normalize(data []byte) ([]byte, error) {
+	return []byte(strings.ToUpper(string(data))), nil
+}
+
+func (c *upperCodec) Diff(want, got []byte) string {
+	if strings.EqualFold(string(want), string(got)) {
+		return ""
+	}
+
+	return string(want) + " != " + string(got)
+}
+
+func TestGolden_DoValueBytes(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		file       string
+		update     bool
+		golden     string
+		v          interface{}
+		want       string
+		wantFatals []string
+	}{
+		{
+			name:   "json read",
+			file:   "resp.json",
+			golden: `{"name":"foo"}`,
+			v:      map[string]string{"name": "bar"},
+			want:   `{"name":"foo"}`,
+		},
+		{
+			name:   "json update",
+			file:   "resp.json",
+			update: true,
+			golden: `{"name":"foo"}`,
+			v:      map[string]string{"name": "bar"},
+			want:   "{\n  \"name\": \"bar\"\n}\n",
+		},
+		{
+			name:   "xml update",
+			file:   "resp.xml",
+			update: true,
+			golden: `<items/>`,
+			v:      &xmlTestStruct{Kind: "a"},
+			want:   "<items kind=\"a\"></items>\n",
+		},
+		{
+			name:   "custom codec update",
+			file:   "resp.txt",
+			update: true,
+			golden: "foo",
+			v:      "bar",
+			want:   "BAR",
+		},
+		{
+			name:   "marshal error",
+			file:   "resp.txt",
+			update: true,
+			golden: "foo",
+			v:      42,
+			wantFatals: []string{
+				"golden: failed to marshal resp.txt: not a string",
+			},
+		},
+		{
+			name:   "unknown extension",
+			file:   "resp.yaml",
+			golden: "foo",
+			v:      "bar",
+			wantFatals: []string{
+				`golden: no codec registered for ".yaml" file extension`,
+			},
+		},
+		{
+			name:   "no extension",
+			file:   "resp",
+			golden: "foo",
+			v:      "bar",
+			wantFatals: []string{
+				`golden: no codec registered for "" file extension`,
+			},
+		},
+		{
+			name:       "empty name",
+			file:       "",
+			v:          "bar",
+			wantFatals: []string{"golden: name cannot be empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			g := New(
+				WithDirname(dir),
+				WithUpdateFunc(func() bool { return tt.update }),
+				WithCodec(".txt", &upperCodec{}),
+			)
+			ft := newFakeT("TestDoValueBytes/" + tt.name)
+
+			f := filepath.Join(
+				dir, "TestDoValueBytes", sanitizeFilename(tt.name), tt.file,
+			)
+			if tt.file != "" {
+				err := os.MkdirAll(filepath.Dir(f), 0o755)
+				require.NoError(t, err)
+				err = os.WriteFile(f, []byte(tt.golden), 0o600)
+				require.NoError(t, err)
+			}
+
+			var got []byte
+			ft.run(func(ft TestingT) {
+				got = g.DoValueBytes(ft, tt.file, tt.v)
+			})
+
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.wantFatals, ft.fatals)
+		})
+	}
+}
+
+func TestGolden_AssertValue(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		file       string
+		update     bool
+		golden     string
+		v          interface{}
+		tolerance  Tolerance
+		want       bool
+		wantFile   string
+		wantErrors []string
+	}{
+		{
+			name:     "json match",
+			file:     "resp.json",
+			golden:   `{"b": 2, "a": 1}`,
+			v:        map[string]int{"a": 1, "b": 2},
+			want:     true,
+			wantFile: `{"b": 2, "a": 1}`,
+		},
+		{
+			name:     "json mismatch",
+			file:     "resp.json",
+			golden:   `{"a": 1}`,
+			v:        map[string]int{"a": 2},
+			want:     false,
+			wantFile: `{"a": 1}`,
+			wantErrors: []string{
+				filepath.Join("json_mismatch", "resp.json") +
+					" does not match:\n$.a: want 1, got 2\n",
+			},
+		},
+		{
+			name:      "json within tolerance",
+			file:      "resp.json",
+			golden:    `{"a": 0.30000000000000004}`,
+			v:         map[string]float64{"a": 0.3},
+			tolerance: Tolerance{Rel: 1e-9},
+			want:      true,
+			wantFile:  `{"a": 0.30000000000000004}`,
+		},
+		{
+			name:      "json outside tolerance",
+			file:      "resp.json",
+			golden:    `{"a": 0.5}`,
+			v:         map[string]float64{"a": 0.75},
+			tolerance: Tolerance{Abs: 0.1},
+			want:      false,
+			wantFile:  `{"a": 0.5}`,
+			wantErrors: []string{
+				"$.a: want 0.5, got 0.75 (delta 0.25)\n",
+			},
+		},
+		{
+			name:     "xml mismatch",
+			file:     "resp.xml",
+			golden:   `<items kind="a"/>`,
+			v:        &xmlTestStruct{Kind: "b"},
+			want:     false,
+			wantFile: `<items kind="a"/>`,
+			wantErrors: []string{
+				filepath.Join("xml_mismatch", "resp.xml") +
+					" does not match:\n/items/@kind: want \"a\", got \"b\"\n",
+			},
+		},
+		{
+			name:     "custom codec match",
+			file:     "resp.txt",
+			golden:   "Foo",
+			v:        "foo",
+			want:     true,
+			wantFile: "Foo",
+		},
+		{
+			name:     "custom codec mismatch with update",
+			file:     "resp.txt",
+			update:   true,
+			golden:   "foo",
+			v:        "bar",
+			want:     true,
+			wantFile: "BAR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			g := New(
+				WithDirname(dir),
+				WithUpdateFunc(func() bool { return tt.update }),
+				WithCodec(".txt", &upperCodec{}),
+				WithTolerance(tt.tolerance.Abs, tt.tolerance.Rel),
+			)
+			ft := newFakeT("TestAssertValue/" + tt.name)
+
+			f := filepath.Join(
+				dir, "TestAssertValue", sanitizeFilename(tt.name), tt.file,
+			)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte(tt.golden), 0o600)
+			require.NoError(t, err)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.AssertValue(ft, tt.file, tt.v) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			b, err := os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, string(b))
+		})
+	}
+}
+
+func TestAssertValue(t *testing.T) {
+	ft := newFakeT("TestAssertValue")
+
+	var got bool
+	ft.run(func(ft TestingT) { got = AssertValue(ft, "", "foo") })
+
+	assert.False(t, got)
+	assert.Equal(t, []string{"golden: name cannot be empty"}, ft.fatals)
+}
+
+func TestDoValueBytes(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+	ft := newFakeT("TestDoValueBytes")
+
+	got := DoValueBytes(ft, "resp.json", map[string]int{"a": 1})
+
+	assert.Equal(t, "{\n  \"a\": 1\n}\n", string(got))
+	assert.False(t, ft.Failed(), ft.Output())
+}
+
+func TestJSONCodec(t *testing.T) {
+	c := &JSONCodec{}
+
+	b, err := c.Marshal(map[string]interface{}{"b": []int{1}, "a": 1.50})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1.5,"b":[1]}`, string(b))
+
+	b, err = c.Normalize([]byte(" {\"b\": [1],\n\"a\":1.50} \n"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"b\": [\n    1\n  ],\n  \"a\": 1.50\n}\n", string(b))
+
+	_, err = c.Normalize([]byte(`{"a":`))
+	assert.EqualError(t, err, "unexpected end of JSON input")
+
+	var v map[string]int
+	err = c.Unmarshal([]byte(`{"a": 1}`), &v)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1}, v)
+
+	assert.Equal(t, "", c.Diff([]byte(`{"a":1}`), []byte(`{"a":1.0}`)))
+	assert.Equal(t, "$.a: want 1, got 2\n",
+		c.Diff([]byte(`{"a":1}`), []byte(`{"a":2}`)),
+	)
+
+	opts := CompareOptions{Tolerance: Tolerance{Abs: 0.5}}
+	assert.Equal(t, "",
+		c.DiffOptions([]byte(`{"a":1}`), []byte(`{"a":1.25}`), opts),
+	)
+	assert.Equal(t, "$.a: want 1, got 2 (delta 1)\n",
+		c.DiffOptions([]byte(`{"a":1}`), []byte(`{"a":2}`), opts),
+	)
+}
+
+func TestXMLCodec(t *testing.T) {
+	c := &XMLCodec{}
+
+	b, err := c.Marshal(&xmlTestItem{ID: "1", Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t,
+		`<xmlTestItem id="1"><name>foo</name></xmlTestItem>`, string(b),
+	)
+
+	b, err = c.Normalize([]byte(`<a y="2" x="1"> <b/> </a>`))
+	require.NoError(t, err)
+	assert.Equal(t, "<a x=\"1\" y=\"2\">\n  <b></b>\n</a>\n", string(b))
+
+	_, err = c.Normalize([]byte(`<a>`))
+	assert.EqualError(t, err, "XML syntax error on line 1: unexpected EOF")
+
+	var v xmlTestItem
+	err = c.Unmarshal([]byte(`<item id="2"><name>bar</name></item>`), &v)
+	require.NoError(t, err)
+	assert.Equal(t, xmlTestItem{ID: "2", Name: "bar"}, v)
+
+	assert.Equal(t, "", c.Diff([]byte(`<a x="1"/>`), []byte(`<a x="1"></a>`)))
+	assert.Equal(t, "/a/@x: want \"1\", got \"2\"\n",
+		c.Diff([]byte(`<a x="1"/>`), []byte(`<a x="2"/>`)),
+	)
+}
@@ -0,0 +1,61 @@
+package golden
+
+import (
+	"math"
+)
+
+// CompareOptions holds options for comparing structured golden file content,
+// like JSON, against actual data.
+type CompareOptions struct {
+	// Tolerance determines how much numbers may differ while still being
+	// considered equal. The zero value requires numbers to be exactly equal.
+	Tolerance Tolerance
+
+	// IgnorePaths holds JSON paths of values which are excluded from
+	// comparison, like "$.meta.createdAt" or "$.items[*].id". Supported are
+	// the root "$", child keys ".name" and ["name"], array indexes "[0]",
+	// wildcards ".*" and "[*]", and recursive descent "..name". Ignoring a
+	// value also ignores everything nested within it.
+	IgnorePaths []string
+}
+
+// Tolerance determines how much two numbers may differ while still being
+// considered equal. Numbers are considered equal if they are within either
+// the absolute or the relative tolerance of each other.
+type Tolerance struct {
+	// Abs is the maximum absolute difference between two numbers.
+	Abs float64
+
+	// Rel is the maximum difference between two numbers, relative to the
+	// larger of their absolute values. For example 1e-9 requires numbers to
+	// agree to about 9 significant digits.
+	Rel float64
+}
+
+// IsZero returns true if no tolerance is allowed, requiring numbers to be
+// exactly equal.
+func (tol Tolerance) IsZero() bool {
+	return tol.Abs <= 0 && tol.Rel <= 0
+}
+
+// Equal returns true if a and b are within tolerance of each other.
+func (tol Tolerance) Equal(a, b float64) bool {
+	if a == b {
+		return true
+	}
+
+	delta := math.Abs(a - b)
+	if delta <= tol.Abs {
+		return true
+	}
+
+	return delta <= tol.Rel*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// compareOptions returns the CompareOptions of s.
+func (s *Golden) compareOptions() CompareOptions {
+	return CompareOptions{
+		Tolerance:   s.Tolerance,
+		IgnorePaths: s.IgnorePaths,
+	}
+}
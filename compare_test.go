@@ -0,0 +1,82 @@
+package golden
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTolerance_Equal(t *testing.T) {
+	tests := []struct {
+		name string
+		tol  Tolerance
+		a    float64
+		b    float64
+		want bool
+	}{
+		{name: "zero equal", a: 1.5, b: 1.5, want: true},
+		{name: "zero not equal", a: 0.30000000000000004, b: 0.3, want: false},
+		{
+			name: "absolute within",
+			tol:  Tolerance{Abs: 0.01},
+			a:    1,
+			b:    1.005,
+			want: true,
+		},
+		{
+			name: "absolute outside",
+			tol:  Tolerance{Abs: 0.01},
+			a:    1,
+			b:    1.02,
+			want: false,
+		},
+		{
+			name: "relative within",
+			tol:  Tolerance{Rel: 1e-3},
+			a:    1000,
+			b:    1001,
+			want: true,
+		},
+		{
+			name: "relative outside",
+			tol:  Tolerance{Rel: 1e-3},
+			a:    1,
+			b:    1.01,
+			want: false,
+		},
+		{
+			name: "relative negative",
+			tol:  Tolerance{Rel: 1e-3},
+			a:    -1000,
+			b:    -999,
+			want: true,
+		},
+		{
+			name: "either",
+			tol:  Tolerance{Abs: 0.1, Rel: 1e-9},
+			a:    0,
+			b:    0.05,
+			want: true,
+		},
+		{
+			name: "nan",
+			tol:  Tolerance{Abs: math.Inf(1)},
+			a:    math.NaN(),
+			b:    math.NaN(),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.tol.Equal(tt.a, tt.b))
+			assert.Equal(t, tt.want, tt.tol.Equal(tt.b, tt.a))
+		})
+	}
+}
+
+func TestTolerance_IsZero(t *testing.T) {
+	assert.True(t, Tolerance{}.IsZero())
+	assert.False(t, Tolerance{Abs: 1e-9}.IsZero())
+	assert.False(t, Tolerance{Rel: 1e-9}.IsZero())
+}
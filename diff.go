@@ -0,0 +1,413 @@
+package golden
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Differ produces a human readable description of the differences between
+// the content of a golden file and the actual data it is compared against. It
+// is used to build failure messages when a golden comparison fails.
+type Differ interface {
+	// Diff returns the differences between want and got, using wantName and
+	// gotName to label each side. An empty string is returned when want and
+	// got are equal.
+	Diff(wantName, gotName string, want, got []byte) string
+}
+
+// UnifiedDiffer is a Differ which produces line-based diffs in the unified
+// diff format, as produced by "diff -u". The diff is computed with the Myers
+// diff algorithm.
+type UnifiedDiffer struct {
+	// Context is the number of unchanged lines shown before and after each
+	// change. Negative values are treated as zero.
+	Context int
+}
+
+var _ Differ = (*UnifiedDiffer)(nil)
+
+// NewUnifiedDiffer returns a new *UnifiedDiffer which shows the given number
+// of context lines around each change.
+func NewUnifiedDiffer(context int) *UnifiedDiffer {
+	return &UnifiedDiffer{Context: context}
+}
+
+// Diff returns a unified diff between want and got.
+func (d *UnifiedDiffer) Diff(
+	wantName, gotName string,
+	want, got []byte,
+) string {
+	return unifiedDiff(
+		wantName, gotName, splitLines(want), splitLines(got), d.Context,
+	)
+}
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// splitLines splits data into lines, keeping the trailing newline character
+// of each line so a missing newline at end of file is detected as a change.
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func unifiedDiff(aName, bName string, a, b []string, context int) string {
+	if context < 0 {
+		context = 0
+	}
+
+	lines := diffLines(a, b)
+
+	// aPos and bPos hold the number of lines from a and b respectively that
+	// come before each index of lines.
+	aPos := make([]int, len(lines)+1)
+	bPos := make([]int, len(lines)+1)
+	for i, l := range lines {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if l.op != diffInsert {
+			aPos[i+1]++
+		}
+		if l.op != diffDelete {
+			bPos[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	for _, h := range diffHunks(lines, context) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(aPos[h[0]], aPos[h[1]]-aPos[h[0]]),
+			hunkRange(bPos[h[0]], bPos[h[1]]-bPos[h[0]]),
+		)
+
+		for _, l := range lines[h[0]:h[1]] {
+			switch l.op {
+			case diffEqual:
+				sb.WriteByte(' ')
+			case diffDelete:
+				sb.WriteByte('-')
+			case diffInsert:
+				sb.WriteByte('+')
+			}
+			sb.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// diffHunks returns the start (inclusive) and end (exclusive) indexes of all
+// hunks within lines, including the given number of context lines around each
+// change. Changes separated by no more than twice the context are merged into
+// a single hunk.
+func diffHunks(lines []diffLine, context int) [][2]int {
+	var hunks [][2]int
+
+	for i := 0; i < len(lines); {
+		if lines[i].op == diffEqual {
+			i++
+
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		end := i
+		for end < len(lines) {
+			if lines[end].op != diffEqual {
+				end++
+
+				continue
+			}
+
+			j := end
+			for j < len(lines) && lines[j].op == diffEqual {
+				j++
+			}
+
+			if j == len(lines) || j-end > 2*context {
+				end += context
+				if end > len(lines) {
+					end = len(lines)
+				}
+
+				break
+			}
+
+			end = j
+		}
+
+		hunks = append(hunks, [2]int{start, end})
+		i = end
+	}
+
+	return hunks
+}
+
+// hunkRange formats the line range of a hunk, where pos is the number of
+// lines preceding the hunk.
+func hunkRange(pos, length int) string {
+	switch length {
+	case 0:
+		return strconv.Itoa(pos) + ",0"
+	case 1:
+		return strconv.Itoa(pos + 1)
+	default:
+		return strconv.Itoa(pos+1) + "," + strconv.Itoa(length)
+	}
+}
+
+// diffLines returns the shortest edit script which transforms a into b,
+// including all unchanged lines.
+func diffLines(a, b []string) []diffLine {
+	return appendDiffLines(make([]diffLine, 0, len(a)+len(b)), a, b)
+}
+
+// myersLimit is the maximum number of edits myers() is used for. Larger edit
+// scripts are divided at their middle snake first, keeping memory use linear
+// in the size of the input, even when it is completely different.
+const myersLimit = 256
+
+// appendDiffLines appends the shortest edit script which transforms a into b
+// to lines. Small edit scripts are found with myers(), while larger ones are
+// recursively divided at their middle snake, as described by the linear space
+// variant of the same algorithm.
+func appendDiffLines(lines []diffLine, a, b []string) []diffLine {
+	// Strip common prefix and suffix, as it is cheap and significantly reduces
+	// the work required by the Myers algorithm for typical inputs.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, l := range a[:prefix] {
+		lines = append(lines, diffLine{op: diffEqual, text: l})
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	switch {
+	case len(ma) == 0:
+		for _, l := range mb {
+			lines = append(lines, diffLine{op: diffInsert, text: l})
+		}
+	case len(mb) == 0:
+		for _, l := range ma {
+			lines = append(lines, diffLine{op: diffDelete, text: l})
+		}
+	default:
+		if l, ok := myers(ma, mb, myersLimit); ok {
+			lines = append(lines, l...)
+
+			break
+		}
+
+		// With the common prefix and suffix stripped, and neither side empty,
+		// the edit script has at least two edits, so both halves are smaller
+		// than the whole.
+		x, y, u, v := middleSnake(ma, mb)
+		lines = appendDiffLines(lines, ma[:x], mb[:y])
+		for _, l := range ma[x:u] {
+			lines = append(lines, diffLine{op: diffEqual, text: l})
+		}
+		lines = appendDiffLines(lines, ma[u:], mb[v:])
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{op: diffEqual, text: l})
+	}
+
+	return lines
+}
+
+// myers implements the Myers diff algorithm, as described in "An O(ND)
+// Difference Algorithm and Its Variations" by Eugene W. Myers. As memory use
+// grows quadratically with the number of edits, it gives up and returns false
+// once more than limit edits are required.
+func myers(a, b []string, limit int) ([]diffLine, bool) {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+
+	// Record a snapshot of v before each step, so the edit script can be
+	// recovered by backtracking once the end has been reached. Only diagonals
+	// -d to d are read when backtracking through step d, so only those are
+	// kept.
+	var trace [][]int
+
+search:
+	for d := 0; ; d++ {
+		if d > maxD || d > limit {
+			return nil, false
+		}
+
+		trace = append(trace,
+			append([]int(nil), v[offset-d:offset+d+1]...),
+		)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = v[d+prevK]
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			lines = append(lines, diffLine{op: diffEqual, text: a[x]})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, diffLine{op: diffInsert, text: b[prevY]})
+			} else {
+				lines = append(lines, diffLine{op: diffDelete, text: a[prevX]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	return lines, true
+}
+
+// middleSnake returns the start (x, y) and end (u, v) of the middle snake of
+// the shortest edit script which transforms a into b. It searches forward from
+// the start and backward from the end at the same time, until the two paths
+// overlap.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+
+	// Furthest reaching x on each diagonal k, forward from the start in vf, and
+	// backward from the end in vb, where vb holds the distance from the end.
+	vf := make([]int, 2*maxD+3)
+	vb := make([]int, 2*maxD+3)
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+			y = x - k
+
+			u, v = x, y
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+			vf[offset+k] = u
+
+			// Backward diagonal delta-k was last extended in step d-1.
+			if c := delta - k; odd && c >= -(d-1) && c <= d-1 &&
+				u+vb[offset+c] >= n {
+				return x, y, u, v
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var bx int
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				bx = vb[offset+k+1]
+			} else {
+				bx = vb[offset+k-1] + 1
+			}
+			by := bx - k
+
+			ex, ey := bx, by
+			for ex < n && ey < m && a[n-1-ex] == b[m-1-ey] {
+				ex++
+				ey++
+			}
+			vb[offset+k] = ex
+
+			// Forward diagonal delta-k was extended in this step.
+			if c := delta - k; !odd && c >= -d && c <= d &&
+				ex+vf[offset+c] >= n {
+				return n - ex, m - ey, n - bx, m - by
+			}
+		}
+	}
+
+	// Unreachable, as the paths always overlap by step maxD.
+	return 0, 0, 0, 0
+}
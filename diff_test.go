@@ -0,0 +1,263 @@
+package golden
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUnifiedDiffer(t *testing.T) {
+	d := NewUnifiedDiffer(5)
+
+	assert.Equal(t, &UnifiedDiffer{Context: 5}, d)
+}
+
+func TestUnifiedDiffer_Diff(t *testing.T) {
+	tests := []struct {
+		name    string
+		context int
+		want    string
+		got     string
+		diff    string
+	}{
+		{
+			name: "both empty",
+			want: "",
+			got:  "",
+			diff: "",
+		},
+		{
+			name: "equal",
+			want: "foo\nbar\n",
+			got:  "foo\nbar\n",
+			diff: "",
+		},
+		{
+			name: "from empty",
+			want: "",
+			got:  "foo\nbar\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+foo\n" +
+				"+bar\n",
+		},
+		{
+			name: "to empty",
+			want: "foo\nbar\n",
+			got:  "",
+			diff: "--- want\n+++ got\n" +
+				"@@ -1,2 +0,0 @@\n" +
+				"-foo\n" +
+				"-bar\n",
+		},
+		{
+			name:    "changed line",
+			context: 3,
+			want:    "a\nb\nc\n",
+			got:     "a\nB\nc\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" a\n" +
+				"-b\n" +
+				"+B\n" +
+				" c\n",
+		},
+		{
+			name:    "single line",
+			context: 3,
+			want:    "foo\n",
+			got:     "bar\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -1 +1 @@\n" +
+				"-foo\n" +
+				"+bar\n",
+		},
+		{
+			name:    "missing newline at end of file",
+			context: 3,
+			want:    "foo\nbar\n",
+			got:     "foo\nbar",
+			diff: "--- want\n+++ got\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				" foo\n" +
+				"-bar\n" +
+				"+bar\n" +
+				"\\ No newline at end of file\n",
+		},
+		{
+			name:    "limited context",
+			context: 1,
+			want:    "1\n2\n3\n4\n5\n6\n7\n",
+			got:     "1\n2\n3\nfour\n5\n6\n7\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -3,3 +3,3 @@\n" +
+				" 3\n" +
+				"-4\n" +
+				"+four\n" +
+				" 5\n",
+		},
+		{
+			name:    "zero context",
+			context: 0,
+			want:    "1\n2\n3\n",
+			got:     "1\ntwo\n3\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -2 +2 @@\n" +
+				"-2\n" +
+				"+two\n",
+		},
+		{
+			name:    "negative context",
+			context: -2,
+			want:    "1\n2\n3\n",
+			got:     "1\n3\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -2 +1,0 @@\n" +
+				"-2\n",
+		},
+		{
+			name:    "separate hunks",
+			context: 1,
+			want:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			got:     "1\ntwo\n3\n4\n5\n6\n7\neight\n9\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+two\n" +
+				" 3\n" +
+				"@@ -7,3 +7,3 @@\n" +
+				" 7\n" +
+				"-8\n" +
+				"+eight\n" +
+				" 9\n",
+		},
+		{
+			name:    "merged hunks",
+			context: 2,
+			want:    "1\n2\n3\n4\n5\n6\n7\n",
+			got:     "1\ntwo\n3\n4\n5\nsix\n7\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -1,7 +1,7 @@\n" +
+				" 1\n" +
+				"-2\n" +
+				"+two\n" +
+				" 3\n" +
+				" 4\n" +
+				" 5\n" +
+				"-6\n" +
+				"+six\n" +
+				" 7\n",
+		},
+		{
+			name:    "insertions and deletions",
+			context: 3,
+			want:    "a\nb\nc\na\nb\nb\na\n",
+			got:     "c\nb\na\nb\na\nc\n",
+			diff: "--- want\n+++ got\n" +
+				"@@ -1,7 +1,6 @@\n" +
+				"-a\n" +
+				"-b\n" +
+				" c\n" +
+				"+b\n" +
+				" a\n" +
+				" b\n" +
+				"-b\n" +
+				" a\n" +
+				"+c\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &UnifiedDiffer{Context: tt.context}
+
+			got := d.Diff("want", "got", []byte(tt.want), []byte(tt.got))
+
+			assert.Equal(t, tt.diff, got)
+		})
+	}
+}
+
+func TestUnifiedDiffer_Diff_large(t *testing.T) {
+	var want, got strings.Builder
+	for i := 0; i < 5000; i++ {
+		want.WriteString("line\n")
+		got.WriteString("line\n")
+		if i == 2500 {
+			got.WriteString("extra\n")
+		}
+	}
+
+	d := &UnifiedDiffer{Context: 1}
+	diff := d.Diff(
+		"want", "got", []byte(want.String()), []byte(got.String()),
+	)
+
+	assert.Equal(t,
+		"--- want\n+++ got\n"+
+			"@@ -2501,2 +2501,3 @@\n"+
+			" line\n"+
+			"+extra\n"+
+			" line\n",
+		diff,
+	)
+}
+
+func TestUnifiedDiffer_Diff_largeDifferent(t *testing.T) {
+	var want, got, diff strings.Builder
+	diff.WriteString("--- want\n+++ got\n@@ -1,5000 +1,5000 @@\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&want, "want %d\n", i)
+		fmt.Fprintf(&got, "got %d\n", i)
+	}
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&diff, "-want %d\n", i)
+	}
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&diff, "+got %d\n", i)
+	}
+
+	d := &UnifiedDiffer{Context: 3}
+
+	assert.Equal(t,
+		diff.String(),
+		d.Diff("want", "got", []byte(want.String()), []byte(got.String())),
+	)
+}
+
+func TestDiffLines_largeEditScript(t *testing.T) {
+	var a, b []string
+	changed := 0
+	for i := 0; i < 3000; i++ {
+		a = append(a, fmt.Sprintf("line %d", i))
+		if i%3 == 0 {
+			b = append(b, fmt.Sprintf("changed %d", i))
+			changed++
+		} else {
+			b = append(b, fmt.Sprintf("line %d", i))
+		}
+	}
+
+	lines := diffLines(a, b)
+
+	var gotA, gotB []string
+	edits := 0
+	for _, l := range lines {
+		if l.op != diffInsert {
+			gotA = append(gotA, l.text)
+		}
+		if l.op != diffDelete {
+			gotB = append(gotB, l.text)
+		}
+		if l.op != diffEqual {
+			edits++
+		}
+	}
+
+	assert.Equal(t, a, gotA)
+	assert.Equal(t, b, gotB)
+	assert.Greater(t, 2*changed, myersLimit)
+	assert.Equal(t, 2*changed, edits)
+}
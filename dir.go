@@ -0,0 +1,241 @@
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DoDir mirrors all files within srcDir into the golden directory of the given
+// TestingT instance when updating, and otherwise compares them against it.
+// Returns true if all files match.
+//
+// This is a wrapper around calling DoDir() on the Default *Golden instance.
+func DoDir(t TestingT, srcDir string) bool {
+	t.Helper()
+
+	return Default.DoDir(t, srcDir)
+}
+
+// DoDir is like Do(), but for whole directory trees, like the output of code
+// generators. Golden files are stored in a directory named after t.Name()
+// with a ".dir" suffix, keeping their own names without any Suffix, for
+// example:
+//
+//	testdata/TestGenerate.dir/main.go
+//	testdata/TestGenerate.dir/pkg/types.go
+//
+// The ".dir" suffix keeps the golden directory separate from the directory
+// holding named golden files, like those used by DoP(), and the golden files
+// of sub-tests.
+//
+// If UpdateTest() returns true, all files within srcDir are written to the
+// golden directory, and any other files within it are removed, as the
+// directory is entirely managed by DoDir(). When UpdateMode() is
+// UpdateMissing, files are only written if the golden directory does not
+// exist.
+//
+// Every file within srcDir is then compared against the golden directory.
+// Files which differ, are missing, or are unexpected, are each reported with
+// t.Errorf(). Scrubbers and Normalization are applied just like for Assert().
+// Returns true if all files match.
+func (s *Golden) DoDir(t TestingT, srcDir string) bool {
+	t.Helper()
+
+	dir, err := s.dirPath(t)
+	if err != nil {
+		s.fail(t, "%s", err.Error())
+
+		return false
+	}
+
+	got, err := readDirFiles(srcDir)
+	if err != nil {
+		s.fail(t, "golden: failed reading %s: %s", srcDir, err.Error())
+
+		return false
+	}
+
+	if s.UpdateTest(t, "") &&
+		(s.UpdateMode() != UpdateMissing || !dirExists(dir)) {
+		err = s.writeDir(t, dir, got)
+		if err != nil {
+			s.fail(t, "%s", err.Error())
+
+			return false
+		}
+	}
+
+	want, err := readDirFiles(dir)
+	if os.IsNotExist(err) {
+		err = ErrNotExist
+	}
+	if err != nil {
+		s.fail(t, "golden: failed reading %s: %s", dir, err.Error())
+
+		return false
+	}
+
+	return s.compareDir(t, dir, want, got)
+}
+
+// compareDir compares the files in want against the files in got, reporting
+// each file which does not match with t.Errorf().
+func (s *Golden) compareDir(
+	t TestingT,
+	dir string,
+	want map[string][]byte,
+	got map[string][]byte,
+) bool {
+	t.Helper()
+
+	names := make([]string, 0, len(want)+len(got))
+	for name := range want {
+		names = append(names, name)
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	ok := true
+	for _, name := range names {
+		f := filepath.Join(dir, filepath.FromSlash(name))
+		touch(f)
+
+		w, wok := want[name]
+		g, gok := got[name]
+
+		switch {
+		case !gok:
+			t.Errorf("golden: %s does not match: file missing from actual", f)
+			ok = false
+		case !wok:
+			t.Errorf("golden: %s does not match: unexpected file in actual", f)
+			ok = false
+		default:
+			diff, match := s.compareBytes(f, s.Normalize(w), s.clean(g))
+			if !match {
+				t.Errorf("golden: %s does not match:\n%s", f, diff)
+				ok = false
+			}
+		}
+	}
+
+	return ok
+}
+
+// writeDir writes files to dir, removing any other files within it.
+func (s *Golden) writeDir(
+	t TestingT,
+	dir string,
+	files map[string][]byte,
+) error {
+	if s.CI() && !s.AllowCIUpdate {
+		return fmt.Errorf(
+			"golden: refusing to write %s: %w", dir, ErrUpdateInCI,
+		)
+	}
+
+	t.Logf("golden: writing golden directory: %s", dir)
+
+	existing, err := readDirFiles(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("golden: failed reading %s: %w", dir, err)
+	}
+
+	for name := range existing {
+		if _, ok := files[name]; ok {
+			continue
+		}
+
+		err = removeFile(dir, filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return fmt.Errorf("golden: failed to remove file: %w", err)
+		}
+	}
+
+	for name, data := range files {
+		f := filepath.Join(dir, filepath.FromSlash(name))
+
+		err = os.MkdirAll(filepath.Dir(f), s.DirMode)
+		if err != nil {
+			return fmt.Errorf("golden: failed to create directory: %w", err)
+		}
+
+		err = os.WriteFile(f, s.clean(data), s.FileMode)
+		if err != nil {
+			return fmt.Errorf("golden: failed to write file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readDirFiles reads all regular files within dir, keyed by their path
+// relative to dir, using forward slashes as separator.
+func readDirFiles(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	err := filepath.Walk(dir,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if path == dir && !info.IsDir() {
+				return errors.New("not a directory")
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			files[filepath.ToSlash(rel)] = b
+
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// removeFile removes file, and any of its parent directories within dir which
+// are left empty.
+func removeFile(dir, file string) error {
+	err := os.Remove(file)
+	if err != nil {
+		return err
+	}
+
+	for d := filepath.Dir(file); d != dir && d != "."; d = filepath.Dir(d) {
+		if os.Remove(d) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func dirExists(dir string) bool {
+	info, err := os.Stat(dir)
+
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,307 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles writes the given files, keyed by slash separated paths relative
+// to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		f := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(f), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(f, []byte(content), 0o600)
+		require.NoError(t, err)
+	}
+}
+
+// readFiles reads all files within dir, keyed by slash separated paths
+// relative to dir.
+func readFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files, err := readDirFiles(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+
+	m := make(map[string]string, len(files))
+	for name, b := range files {
+		m[name] = string(b)
+	}
+
+	return m
+}
+
+func TestGolden_DoDir(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		update     bool
+		mode       UpdateMode
+		golden     map[string]string
+		src        map[string]string
+		want       bool
+		wantFiles  map[string]string
+		wantErrors []string
+		wantFatals []string
+	}{
+		{
+			name:      "match",
+			golden:    map[string]string{"a.txt": "a", "sub/b.txt": "b"},
+			src:       map[string]string{"a.txt": "a", "sub/b.txt": "b"},
+			want:      true,
+			wantFiles: map[string]string{"a.txt": "a", "sub/b.txt": "b"},
+		},
+		{
+			name: "mismatch",
+			golden: map[string]string{
+				"a.txt": "a\n", "sub/b.txt": "b\n", "removed.txt": "r\n",
+			},
+			src: map[string]string{
+				"a.txt": "a\n", "sub/b.txt": "B\n", "added.txt": "x\n",
+			},
+			want: false,
+			wantFiles: map[string]string{
+				"a.txt": "a\n", "sub/b.txt": "b\n", "removed.txt": "r\n",
+			},
+			wantErrors: []string{
+				filepath.Join("mismatch.dir", "added.txt") +
+					" does not match: unexpected file in actual",
+				filepath.Join("mismatch.dir", "removed.txt") +
+					" does not match: file missing from actual",
+				filepath.Join("mismatch.dir", "sub", "b.txt") +
+					" does not match:\n--- ",
+				"@@ -1 +1 @@\n-b\n+B\n",
+			},
+		},
+		{
+			name:   "update",
+			update: true,
+			golden: map[string]string{
+				"a.txt": "old", "stale/c.txt": "c", "sub/d.txt": "d",
+			},
+			src:  map[string]string{"a.txt": "a", "sub/b.txt": "b"},
+			want: true,
+			wantFiles: map[string]string{
+				"a.txt": "a", "sub/b.txt": "b",
+			},
+		},
+		{
+			name:      "update missing with existing directory",
+			update:    true,
+			mode:      UpdateMissing,
+			golden:    map[string]string{"a.txt": "old"},
+			src:       map[string]string{"a.txt": "a"},
+			want:      false,
+			wantFiles: map[string]string{"a.txt": "old"},
+			wantErrors: []string{
+				"update_missing_with_existing_directory",
+			},
+		},
+		{
+			name:      "update missing without directory",
+			update:    true,
+			mode:      UpdateMissing,
+			src:       map[string]string{"a.txt": "a"},
+			want:      true,
+			wantFiles: map[string]string{"a.txt": "a"},
+		},
+		{
+			name: "no golden directory",
+			src:  map[string]string{"a.txt": "a"},
+			want: false,
+			wantFatals: []string{
+				"golden: failed reading ",
+				"no_golden_directory.dir: golden file does not exist",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := t.TempDir()
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+				WithUpdateMode(tt.mode),
+			)
+			ft := newFakeT("TestDoDir/" + tt.name)
+
+			dir, err := g.dirPath(ft)
+			require.NoError(t, err)
+			writeFiles(t, dir, tt.golden)
+			writeFiles(t, src, tt.src)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.DoDir(ft, src) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, len(ft.errors) > 0)
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+			assert.Equal(t, len(tt.wantFatals) > 0, len(ft.fatals) > 0)
+			for _, msg := range tt.wantFatals {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			assert.Equal(t, tt.wantFiles, readFiles(t, dir))
+			if tt.update {
+				_, err = os.Stat(filepath.Join(dir, "stale"))
+				assert.True(t, os.IsNotExist(err))
+			}
+		})
+	}
+}
+
+func TestGolden_DoDir_ScrubAndNormalize(t *testing.T) {
+	t.Setenv("CI", "")
+
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"out.txt": "id 6ba7b810-9dad-11d1-80b4-00c04fd430c8  \r\n",
+	})
+
+	for _, update := range []bool{true, false} {
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return update }),
+			WithScrubbers(UUIDScrubber()),
+			WithNormalization(NormalizeAll),
+		)
+		ft := newFakeT("TestDoDir_ScrubAndNormalize")
+		dir, err := g.dirPath(ft)
+		require.NoError(t, err)
+		if !update {
+			writeFiles(t, dir, map[string]string{"out.txt": "id <UUID-1>\r\n"})
+		}
+
+		assert.True(t, g.DoDir(ft, src))
+		assert.False(t, ft.Failed(), ft.Output())
+
+		if update {
+			assert.Equal(t,
+				map[string]string{"out.txt": "id <UUID-1>\n"},
+				readFiles(t, dir),
+			)
+		}
+	}
+}
+
+func TestGolden_DoDir_Errors(t *testing.T) {
+	t.Setenv("CI", "")
+
+	t.Run("missing source directory", func(t *testing.T) {
+		g := New(WithDirname(t.TempDir()))
+		ft := newFakeT("TestDoDir_Errors")
+		src := filepath.Join(t.TempDir(), "missing")
+
+		var got bool
+		ft.run(func(ft TestingT) { got = g.DoDir(ft, src) })
+
+		assert.False(t, got)
+		require.Len(t, ft.fatals, 1)
+		assert.Contains(t, ft.fatals[0], "golden: failed reading "+src)
+	})
+
+	t.Run("source is a file", func(t *testing.T) {
+		g := New(WithDirname(t.TempDir()))
+		ft := newFakeT("TestDoDir_Errors")
+		src := filepath.Join(t.TempDir(), "file")
+		err := os.WriteFile(src, []byte("x"), 0o600)
+		require.NoError(t, err)
+
+		var got bool
+		ft.run(func(ft TestingT) { got = g.DoDir(ft, src) })
+
+		assert.False(t, got)
+		assert.Equal(t,
+			[]string{"golden: failed reading " + src + ": not a directory"},
+			ft.fatals,
+		)
+	})
+
+	t.Run("update in CI", func(t *testing.T) {
+		t.Setenv("CI", "true")
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return true }),
+		)
+		ft := newFakeT("TestDoDir_Errors")
+		src := t.TempDir()
+
+		var got bool
+		ft.run(func(ft TestingT) { got = g.DoDir(ft, src) })
+
+		assert.False(t, got)
+		require.Len(t, ft.fatals, 1)
+		assert.Contains(t, ft.fatals[0], ErrUpdateInCI.Error())
+	})
+
+	t.Run("no test name", func(t *testing.T) {
+		g := New(WithDirname(t.TempDir()))
+		ft := newFakeT("")
+
+		var got bool
+		ft.run(func(ft TestingT) { got = g.DoDir(ft, t.TempDir()) })
+
+		assert.False(t, got)
+		assert.Equal(t,
+			[]string{"golden: could not determine filename"}, ft.fatals,
+		)
+	})
+}
+
+func TestDoDir(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+	ft := newFakeT("TestDoDir")
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "a"})
+
+	assert.True(t, DoDir(ft, src))
+	assert.False(t, ft.Failed(), ft.Output())
+}
+
+func TestGolden_DoDir_KeepsNamedGoldenFiles(t *testing.T) {
+	t.Setenv("CI", "")
+
+	dir := t.TempDir()
+	g := New(
+		WithDirname(dir),
+		WithUpdateFunc(func() bool { return true }),
+	)
+	ft := newFakeT("TestDoDir_KeepsNamedGoldenFiles")
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "a"})
+
+	assert.True(t, g.AssertP(ft, "json", []byte("{}")))
+	assert.True(t, g.DoDir(ft, src))
+	require.False(t, ft.Failed(), ft.Output())
+
+	gdir, err := g.dirPath(ft)
+	require.NoError(t, err)
+	assert.Equal(t,
+		filepath.Join(dir, "TestDoDir_KeepsNamedGoldenFiles.dir"), gdir,
+	)
+	assert.Equal(t, map[string]string{"a.txt": "a"}, readFiles(t, gdir))
+
+	g = g.With(WithUpdateFunc(func() bool { return false }))
+	assert.True(t, g.AssertP(ft, "json", []byte("{}")))
+	assert.True(t, g.DoDir(ft, src))
+	assert.False(t, ft.Failed(), ft.Output())
+}
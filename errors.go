@@ -0,0 +1,39 @@
+package golden
+
+import (
+	"errors"
+	"os"
+)
+
+var (
+	// ErrNotExist is returned when a golden file does not exist. Errors
+	// matching ErrNotExist also match os.ErrNotExist when checked with
+	// errors.Is().
+	ErrNotExist error = notExistError{}
+
+	// ErrEmptyName is returned when an empty name is given to any of the "P"
+	// suffixed functions.
+	ErrEmptyName = errors.New("name cannot be empty")
+
+	// ErrNoTestName is returned when the golden filename cannot be determined,
+	// due to t.Name() returning an empty string.
+	ErrNoTestName = errors.New("could not determine filename")
+
+	// ErrUpdateInCI is returned when attempting to write a golden file while
+	// running in CI, unless AllowCIUpdate is enabled.
+	ErrUpdateInCI = errors.New("golden files must not be updated in CI")
+
+	// ErrUnexpectedRequest is returned by the http.RoundTripper returned by
+	// Transport(), when replaying a request which is not in the cassette.
+	ErrUnexpectedRequest = errors.New("unexpected request")
+)
+
+type notExistError struct{}
+
+func (notExistError) Error() string {
+	return "golden file does not exist"
+}
+
+func (notExistError) Is(target error) bool {
+	return target == os.ErrNotExist
+}
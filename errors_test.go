@@ -0,0 +1,20 @@
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrNotExist(t *testing.T) {
+	assert.EqualError(t, ErrNotExist, "golden file does not exist")
+	assert.True(t, errors.Is(ErrNotExist, os.ErrNotExist))
+	assert.False(t, errors.Is(ErrNotExist, os.ErrExist))
+
+	wrapped := fmt.Errorf("golden: failed reading foo.golden: %w", ErrNotExist)
+	assert.True(t, errors.Is(wrapped, ErrNotExist))
+	assert.True(t, errors.Is(wrapped, os.ErrNotExist))
+}
@@ -95,3 +95,47 @@ func TestExampleMyStructTabularP(t *testing.T) {
 		})
 	}
 }
+
+// TestExampleMyStructAssert reads/writes the following golden file:
+//
+//	testdata/TestExampleMyStructAssert.golden
+func TestExampleMyStructAssert(t *testing.T) {
+	got, err := json.Marshal(&MyStruct{Foo: "Bar"})
+	require.NoError(t, err)
+
+	golden.Assert(t, got)
+}
+
+// TestExampleMyStructJSON reads/writes the following golden file:
+//
+//	testdata/TestExampleMyStructJSON.golden
+func TestExampleMyStructJSON(t *testing.T) {
+	golden.AssertJSON(t, &MyStruct{Foo: "Bar"})
+}
+
+// TestExampleMyStructXML reads/writes the following golden file:
+//
+//	testdata/TestExampleMyStructXML.golden
+func TestExampleMyStructXML(t *testing.T) {
+	golden.AssertXML(t, &MyStruct{Foo: "Bar"})
+}
+
+// TestExampleMyStructDoValue reads/writes the following golden file:
+//
+//	testdata/TestExampleMyStructDoValue.golden
+func TestExampleMyStructDoValue(t *testing.T) {
+	got := &MyStruct{Foo: "Bar"}
+
+	want := golden.DoValue(t, got)
+
+	assert.Equal(t, want, got)
+}
+
+// TestExampleMyStructValue reads/writes the following golden files:
+//
+//	testdata/TestExampleMyStructValue/resp.json
+//	testdata/TestExampleMyStructValue/resp.xml
+func TestExampleMyStructValue(t *testing.T) {
+	golden.AssertValue(t, "resp.json", &MyStruct{Foo: "Bar"})
+	golden.AssertValue(t, "resp.xml", &MyStruct{Foo: "Bar"})
+}
@@ -0,0 +1,41 @@
+package golden
+
+// FailMode determines how tests are failed when golden files cannot be found,
+// read, or written.
+type FailMode int
+
+const (
+	// FailFatal fails tests by calling t.Fatalf(), stopping the test
+	// immediately.
+	FailFatal FailMode = iota
+
+	// FailError fails tests by calling t.Errorf(), allowing the test to
+	// continue and report further failures. Functions returning golden file
+	// content return nil in such cases.
+	FailError
+)
+
+// String returns the name of the fail mode.
+func (m FailMode) String() string {
+	switch m {
+	case FailFatal:
+		return "fatal"
+	case FailError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// fail marks the test as failed according to the FailMode of s.
+func (s *Golden) fail(t TestingT, format string, args ...interface{}) {
+	t.Helper()
+
+	if s.FailMode == FailError {
+		t.Errorf(format, args...)
+
+		return
+	}
+
+	t.Fatalf(format, args...)
+}
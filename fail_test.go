@@ -0,0 +1,115 @@
+package golden
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailMode_String(t *testing.T) {
+	tests := []struct {
+		mode FailMode
+		want string
+	}{
+		{mode: FailFatal, want: "fatal"},
+		{mode: FailError, want: "error"},
+		{mode: FailMode(99), want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mode.String())
+		})
+	}
+}
+
+func TestGolden_fail(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       FailMode
+		wantErrors []string
+		wantFatals []string
+		wantReturn bool
+	}{
+		{
+			name:       "FailFatal",
+			mode:       FailFatal,
+			wantFatals: []string{"golden: oops 42"},
+			wantReturn: false,
+		},
+		{
+			name:       "FailError",
+			mode:       FailError,
+			wantErrors: []string{"golden: oops 42"},
+			wantReturn: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Golden{FailMode: tt.mode}
+			ft := newFakeT("TestGolden_fail")
+
+			returned := false
+			ft.run(func(ft TestingT) {
+				g.fail(ft, "golden: oops %d", 42)
+				returned = true
+			})
+
+			assert.Equal(t, tt.wantErrors, ft.errors)
+			assert.Equal(t, tt.wantFatals, ft.fatals)
+			assert.Equal(t, tt.wantReturn, returned)
+		})
+	}
+}
+
+func TestFailError(t *testing.T) {
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return false }),
+		WithFailMode(FailError),
+	)
+	ft := newFakeT("TestFailError")
+
+	var (
+		completed bool
+		do        []byte
+		doP       []byte
+		get       []byte
+		getP      []byte
+		assertOK  bool
+		assertPOK bool
+	)
+	ft.run(func(ft TestingT) {
+		do = g.Do(ft, []byte("foo"))
+		doP = g.DoP(ft, "named", []byte("foo"))
+		get = g.Get(ft)
+		getP = g.GetP(ft, "named")
+		assertOK = g.Assert(ft, []byte("foo"))
+		assertPOK = g.AssertP(ft, "named", []byte("foo"))
+		g.SetP(ft, "", []byte("foo"))
+		completed = true
+	})
+
+	assert.True(t, completed)
+	assert.Nil(t, do)
+	assert.Nil(t, doP)
+	assert.Nil(t, get)
+	assert.Nil(t, getP)
+	assert.False(t, assertOK)
+	assert.False(t, assertPOK)
+	assert.Empty(t, ft.fatals)
+	assert.Equal(t, []string{
+		"golden: failed reading " + g.File(ft) +
+			": golden file does not exist",
+		"golden: failed reading " + g.FileP(ft, "named") +
+			": golden file does not exist",
+		"golden: failed reading " + g.File(ft) +
+			": golden file does not exist",
+		"golden: failed reading " + g.FileP(ft, "named") +
+			": golden file does not exist",
+		"golden: failed reading " + g.File(ft) +
+			": golden file does not exist",
+		"golden: failed reading " + g.FileP(ft, "named") +
+			": golden file does not exist",
+		"golden: name cannot be empty",
+	}, ft.errors)
+}
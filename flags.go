@@ -0,0 +1,202 @@
+package golden
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var falsyStrings = []string{"", "0", "n", "f", "no", "off", "false"}
+
+var (
+	flagMux    sync.RWMutex
+	flagUpdate string
+	flagRun    string
+)
+
+// RegisterFlags registers the following flags on the given *flag.FlagSet,
+// providing an alternative to environment variables for updating golden files:
+//
+//	-golden.update
+//		Update golden files. Accepts the same values as the GOLDEN_UPDATE
+//		environment variable, including "missing". Passing the flag without a
+//		value is equivalent to "true".
+//	-golden.run regexp
+//		Only update golden files matching regexp, just like the
+//		GOLDEN_UPDATE_RUN environment variable.
+//
+// Typically this is called with flag.CommandLine from an init() function or
+// TestMain() within a test file. Both flags are respected by the Default
+// instance and any instance created with New(), as DefaultUpdateFunc and
+// DefaultUpdateModeFunc consider the -golden.update flag in addition to the
+// GOLDEN_UPDATE environment variable:
+//
+//	func TestMain(m *testing.M) {
+//		golden.RegisterFlags(flag.CommandLine)
+//
+//		os.Exit(m.Run())
+//	}
+//
+// Instances with a custom UpdateFunc can use the WithFlags() option to also
+// consider the -golden.update flag.
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(updateFlag{}, "golden.update",
+		"update golden files, set to \"missing\" to only create missing "+
+			"golden files",
+	)
+	fs.Var(runFlag{}, "golden.run",
+		"only update golden files matching `regexp`",
+	)
+}
+
+// FlagUpdateFunc checks if the -golden.update flag registered by
+// RegisterFlags() is set to one of "1", "y", "t", "yes", "on", "true", or
+// "missing". Always returns false if flags have not been registered.
+func FlagUpdateFunc() bool {
+	flagMux.RLock()
+	defer flagMux.RUnlock()
+
+	return isUpdateString(flagUpdate)
+}
+
+// FlagUpdateModeFunc returns UpdateMissing if the -golden.update flag
+// registered by RegisterFlags() is set to "missing", and UpdateAll otherwise.
+func FlagUpdateModeFunc() UpdateMode {
+	flagMux.RLock()
+	defer flagMux.RUnlock()
+
+	if strings.EqualFold(flagUpdate, missingString) {
+		return UpdateMissing
+	}
+
+	return UpdateAll
+}
+
+// FlagUpdateRunFunc checks if the golden file belonging to the given TestingT
+// instance and optional name matches the regular expression given to the
+// -golden.run flag registered by RegisterFlags(). Matching works the same as
+// EnvUpdateRunFunc().
+//
+// Returns true if the flag is not set or is empty. If the regular expression is
+// invalid, the test is failed by calling t.Fatalf(), and false is returned.
+func FlagUpdateRunFunc(t TestingT, name string) bool {
+	t.Helper()
+
+	ok, err := flagUpdateRun(t, name)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+
+		return false
+	}
+
+	return ok
+}
+
+func flagUpdateRun(t TestingT, name string) (bool, error) {
+	flagMux.RLock()
+	pattern := flagRun
+	flagMux.RUnlock()
+
+	return matchUpdateRun(t, "-golden.run flag", pattern, name)
+}
+
+// EnvOrFlagUpdateFunc returns true if either EnvUpdateFunc() or
+// FlagUpdateFunc() returns true.
+func EnvOrFlagUpdateFunc() bool {
+	return EnvUpdateFunc() || FlagUpdateFunc()
+}
+
+// EnvOrFlagUpdateModeFunc returns the result of FlagUpdateModeFunc() when the
+// -golden.update flag enables updates, and of EnvUpdateModeFunc() otherwise.
+func EnvOrFlagUpdateModeFunc() UpdateMode {
+	if FlagUpdateFunc() {
+		return FlagUpdateModeFunc()
+	}
+
+	return EnvUpdateModeFunc()
+}
+
+// WithFlags makes a Golden instance consider the -golden.update flag
+// registered by RegisterFlags(), in addition to its current UpdateFunc and
+// UpdateModeFunc. When the flag enables updates, its update mode takes
+// precedence.
+//
+// This is only needed for instances with a custom UpdateFunc, as
+// DefaultUpdateFunc and DefaultUpdateModeFunc already consider the flag. As
+// options are applied in order, WithFlags() should be given after any
+// WithUpdateFunc() and WithUpdateModeFunc() options.
+func WithFlags() Option {
+	return func(g *Golden) {
+		updateModeFunc := g.UpdateModeFunc
+
+		g.UpdateFunc = AnyUpdateFunc(g.UpdateFunc, FlagUpdateFunc)
+		g.UpdateModeFunc = func() UpdateMode {
+			if FlagUpdateFunc() || updateModeFunc == nil {
+				return FlagUpdateModeFunc()
+			}
+
+			return updateModeFunc()
+		}
+	}
+}
+
+type updateFlag struct{}
+
+func (updateFlag) String() string {
+	flagMux.RLock()
+	defer flagMux.RUnlock()
+
+	return flagUpdate
+}
+
+func (updateFlag) Set(value string) error {
+	if !isUpdateString(value) && !isFalsyString(value) {
+		return fmt.Errorf("invalid value %q", value)
+	}
+
+	flagMux.Lock()
+	defer flagMux.Unlock()
+
+	flagUpdate = value
+
+	return nil
+}
+
+// IsBoolFlag allows the flag to be given without a value.
+func (updateFlag) IsBoolFlag() bool {
+	return true
+}
+
+type runFlag struct{}
+
+func (runFlag) String() string {
+	flagMux.RLock()
+	defer flagMux.RUnlock()
+
+	return flagRun
+}
+
+func (runFlag) Set(value string) error {
+	if _, err := regexp.Compile(value); err != nil {
+		return err
+	}
+
+	flagMux.Lock()
+	defer flagMux.Unlock()
+
+	flagRun = value
+
+	return nil
+}
+
+func isFalsyString(s string) bool {
+	for _, v := range falsyStrings {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+
+	return false
+}
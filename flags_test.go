@@ -0,0 +1,311 @@
+package golden
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseFlags registers golden flags on a new *flag.FlagSet and parses the
+// given arguments, restoring the original flag values after the test.
+func parseFlags(t *testing.T, args ...string) error {
+	t.Helper()
+
+	flagMux.RLock()
+	update, run := flagUpdate, flagRun
+	flagMux.RUnlock()
+
+	t.Cleanup(func() {
+		flagMux.Lock()
+		defer flagMux.Unlock()
+
+		flagUpdate, flagRun = update, run
+	})
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterFlags(fs)
+
+	return fs.Parse(args)
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+	RegisterFlags(fs)
+
+	update := fs.Lookup("golden.update")
+	require.NotNil(t, update)
+	assert.Equal(t, "", update.DefValue)
+
+	run := fs.Lookup("golden.run")
+	require.NotNil(t, run)
+	assert.Equal(t, "", run.DefValue)
+}
+
+func TestFlagUpdateFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     bool
+		wantMode UpdateMode
+		wantErr  string
+	}{
+		{
+			name:     "not set",
+			want:     false,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "without value",
+			args:     []string{"-golden.update"},
+			want:     true,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "set to true",
+			args:     []string{"-golden.update=true"},
+			want:     true,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "set to Yes (mixed case)",
+			args:     []string{"-golden.update=Yes"},
+			want:     true,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "set to false",
+			args:     []string{"-golden.update=false"},
+			want:     false,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "set to 0",
+			args:     []string{"-golden.update=0"},
+			want:     false,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "set to missing",
+			args:     []string{"-golden.update=missing"},
+			want:     true,
+			wantMode: UpdateMissing,
+		},
+		{
+			name:     "set to invalid value",
+			args:     []string{"-golden.update=foobarnopebbq"},
+			want:     false,
+			wantMode: UpdateAll,
+			wantErr: `invalid boolean value "foobarnopebbq" for ` +
+				`-golden.update: invalid value "foobarnopebbq"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseFlags(t, tt.args...)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, FlagUpdateFunc())
+			assert.Equal(t, tt.wantMode, FlagUpdateModeFunc())
+		})
+	}
+}
+
+func TestFlagUpdateRunFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		testName string
+		named    string
+		want     bool
+		wantErr  string
+	}{
+		{
+			name:     "not set",
+			testName: "TestFoo",
+			want:     true,
+		},
+		{
+			name:     "matching name",
+			args:     []string{"-golden.run", "TestFoo/.*json"},
+			testName: "TestFoo/bar",
+			named:    "json",
+			want:     true,
+		},
+		{
+			name:     "non-matching name",
+			args:     []string{"-golden.run", "TestFoo/.*json"},
+			testName: "TestFoo/bar",
+			named:    "xml",
+			want:     false,
+		},
+		{
+			name:     "invalid regular expression",
+			args:     []string{"-golden.run", "Test(Foo"},
+			testName: "TestFoo",
+			want:     true,
+			wantErr: "invalid value \"Test(Foo\" for flag -golden.run: " +
+				"error parsing regexp: missing closing ): `Test(Foo`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseFlags(t, tt.args...)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			got := FlagUpdateRunFunc(newFakeT(tt.testName), tt.named)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEnvOrFlagUpdateFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		args     []string
+		want     bool
+		wantMode UpdateMode
+	}{
+		{
+			name:     "neither set",
+			want:     false,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "env set",
+			env:      "missing",
+			want:     true,
+			wantMode: UpdateMissing,
+		},
+		{
+			name:     "flag set",
+			args:     []string{"-golden.update=missing"},
+			want:     true,
+			wantMode: UpdateMissing,
+		},
+		{
+			name:     "flag mode takes precedence when enabled",
+			env:      "missing",
+			args:     []string{"-golden.update"},
+			want:     true,
+			wantMode: UpdateAll,
+		},
+		{
+			name:     "flag disabled",
+			env:      "missing",
+			args:     []string{"-golden.update=false"},
+			want:     true,
+			wantMode: UpdateMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOLDEN_UPDATE", tt.env)
+			err := parseFlags(t, tt.args...)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, EnvOrFlagUpdateFunc())
+			assert.Equal(t, tt.wantMode, EnvOrFlagUpdateModeFunc())
+		})
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		t.Setenv("GOLDEN_UPDATE", "")
+		err := parseFlags(t, "-golden.update")
+		require.NoError(t, err)
+
+		assert.True(t, New().Update())
+	})
+}
+
+func TestWithFlags(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		updateFunc     UpdateFunc
+		updateModeFunc UpdateModeFunc
+		want           bool
+		wantMode       UpdateMode
+	}{
+		{
+			name:       "neither enabled",
+			updateFunc: func() bool { return false },
+			want:       false,
+			wantMode:   UpdateAll,
+		},
+		{
+			name:       "flag enabled",
+			args:       []string{"-golden.update"},
+			updateFunc: func() bool { return false },
+			want:       true,
+			wantMode:   UpdateAll,
+		},
+		{
+			name:       "UpdateFunc enabled",
+			updateFunc: func() bool { return true },
+			want:       true,
+			wantMode:   UpdateAll,
+		},
+		{
+			name:           "UpdateFunc enabled with missing mode",
+			updateFunc:     func() bool { return true },
+			updateModeFunc: func() UpdateMode { return UpdateMissing },
+			want:           true,
+			wantMode:       UpdateMissing,
+		},
+		{
+			name:           "flag enabled with missing mode",
+			args:           []string{"-golden.update=missing"},
+			updateFunc:     func() bool { return false },
+			updateModeFunc: func() UpdateMode { return UpdateAll },
+			want:           true,
+			wantMode:       UpdateMissing,
+		},
+		{
+			name:           "flag mode takes precedence when enabled",
+			args:           []string{"-golden.update=true"},
+			updateFunc:     func() bool { return true },
+			updateModeFunc: func() UpdateMode { return UpdateMissing },
+			want:           true,
+			wantMode:       UpdateAll,
+		},
+		{
+			name:     "nil UpdateFunc and UpdateModeFunc",
+			args:     []string{"-golden.update=missing"},
+			want:     true,
+			wantMode: UpdateMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseFlags(t, tt.args...)
+			require.NoError(t, err)
+
+			g := &Golden{
+				UpdateFunc:     tt.updateFunc,
+				UpdateModeFunc: tt.updateModeFunc,
+			}
+
+			WithFlags()(g)
+
+			assert.Equal(t, tt.want, g.Update())
+			assert.Equal(t, tt.wantMode, g.UpdateMode())
+		})
+	}
+}
@@ -25,15 +25,41 @@
 //
 // The call to golden.Do() is equivalent to:
 //
-//	if golden.Update() {
+//	if golden.UpdateTest(t, "") {
 //		golden.Set(t, got)
 //	}
 //	want := golden.Get(t)
 //
+// Except that when only missing golden files are created, as described below,
+// Set() is not called if the golden file already exists.
+//
 // To update the golden file (have golden.Update() return true), simply set the
 // GOLDEN_UPDATE environment variable to one of "1", "y", "t", "yes", "on", or
 // "true" when running tests.
 //
+// To only create golden files which do not exist yet, without touching any
+// existing golden files, set the GOLDEN_UPDATE environment variable to
+// "missing" instead. Existing golden files are then read and compared against
+// as usual.
+//
+// To only update some golden files, set the GOLDEN_UPDATE_RUN environment
+// variable to a regular expression. Only golden files where t.Name(), followed
+// by "/" and the name given to the "P" suffixed functions if any, matches the
+// regular expression are updated. For example:
+//
+//	GOLDEN_UPDATE=1 GOLDEN_UPDATE_RUN='TestFoo/.*json' go test ./...
+//
+// Alternatively, golden files can be updated with the -golden.update and
+// -golden.run flags, once registered with RegisterFlags():
+//
+//	go test ./... -golden.update -golden.run='TestFoo/.*json'
+//
+// Golden files are never written when running in CI, as determined by the CI
+// environment variable being set to a truthy value. Any attempt to do so fails
+// the test, as updating golden files in CI would cause tests to pass without
+// actually verifying anything. This can be overridden with the AllowCIUpdate
+// field on a custom *Golden instance.
+//
 // # Sub-Tests
 //
 // As the golden filename is based on t.Name(), it works with sub-tests too,
@@ -116,14 +142,326 @@
 //	testdata/TestExampleMyStructTabularP/empty_struct/xml.golden
 //	testdata/TestExampleMyStructTabularP/full_struct/json.golden
 //	testdata/TestExampleMyStructTabularP/full_struct/xml.golden
+//
+// # Assertions
+//
+// Instead of comparing the result of Do() yourself, Assert() and AssertP()
+// perform the same update-or-read cycle, and then compare the golden file
+// content against the given data:
+//
+//	func TestExampleMyStructAssert(t *testing.T) {
+//		got, err := json.Marshal(&MyStruct{Foo: "Bar"})
+//		require.NoError(t, err)
+//
+//		golden.Assert(t, got)
+//	}
+//
+// On mismatch the test is marked as failed via t.Errorf() with a message naming
+// the golden file, and a unified diff between the golden file content and the
+// given data:
+//
+//	golden: testdata/TestExampleMyStructAssert.golden does not match:
+//	--- testdata/TestExampleMyStructAssert.golden
+//	+++ actual
+//	@@ -1 +1 @@
+//	-{"foo":"Bar"}
+//	\ No newline at end of file
+//	+{"foo":"Baz"}
+//	\ No newline at end of file
+//
+// The diff output can be customized by setting a different Differ on a custom
+// *Golden instance created with New().
+//
+// When running tests in CI it can be useful to inspect the actual data which
+// failed to match. With ActualFiles enabled, the actual data is written next to
+// the golden file with a ".actual" suffix on mismatch:
+//
+//	testdata/TestExampleMyStructAssert.golden.actual
+//
+// Any such ".actual" file is removed once the comparison succeeds again.
+//
+// # JSON
+//
+// DoJSON(), AssertJSON() and their "P" suffixed variants marshal any value as
+// JSON indented with two spaces, and store it using the usual golden file
+// naming:
+//
+//	func TestExampleMyStructJSON(t *testing.T) {
+//		golden.AssertJSON(t, &MyStruct{Foo: "Bar"})
+//	}
+//
+// AssertJSON() compares the golden file and the marshaled value semantically,
+// so key order and whitespace do not matter. Mismatches are reported by JSON
+// path:
+//
+//	golden: testdata/TestExampleMyStructJSON.golden does not match:
+//	$.foo: want "Bar", got "Baz"
+//
+// Numbers which differ slightly, for example due to floating point differences
+// between architectures, can be considered equal by setting a Tolerance:
+//
+//	g := golden.New(golden.WithTolerance(1e-12, 1e-9))
+//
+// Values which are intentionally nondeterministic can be excluded from the
+// comparison by their JSON path. They are still written to the golden file
+// when updating:
+//
+//	g := golden.New(golden.IgnorePaths("$.items[*].id", "$.meta.createdAt"))
+//
+// # XML
+//
+// Similarly, DoXML(), AssertXML() and their "P" suffixed variants marshal any
+// value as canonical XML, with attributes sorted, insignificant whitespace
+// removed, namespace prefixes normalized, and indented with two spaces:
+//
+//	func TestExampleMyStructXML(t *testing.T) {
+//		golden.AssertXML(t, &MyStruct{Foo: "Bar"})
+//	}
+//
+// AssertXML() also canonicalizes the golden file content before comparing it,
+// and reports mismatches by element path:
+//
+//	golden: testdata/TestExampleMyStructXML.golden does not match:
+//	/MyStruct/Foo/text(): want "Bar", got "Baz"
+//
+// # Codecs
+//
+// DoValueP(), DoValueBytes() and AssertValue() store typed values using the
+// Codec registered for the file extension of the given name. The Suffix is not
+// used, so a single test can hold golden files of different formats:
+//
+//	func TestExampleMyStructValue(t *testing.T) {
+//		golden.AssertValue(t, "resp.json", &MyStruct{Foo: "Bar"})
+//		golden.AssertValue(t, "resp.xml", &MyStruct{Foo: "Bar"})
+//	}
+//
+// The above example will read/write to:
+//
+//	testdata/TestExampleMyStructValue/resp.json
+//	testdata/TestExampleMyStructValue/resp.xml
+//
+// Codecs for ".json" and ".xml" are registered by default. Other formats can
+// be supported by implementing the Codec interface, and registering it with
+// WithCodec().
+//
+// # Typed Values
+//
+// DoValue() stores any value as JSON, and unmarshals the golden file content
+// back into a value of the same type, so it can be compared against the
+// original value directly:
+//
+//	func TestExampleMyStructDoValue(t *testing.T) {
+//		got := &MyStruct{Foo: "Bar"}
+//
+//		want := golden.DoValue(t, got)
+//
+//		assert.Equal(t, want, got)
+//	}
+//
+// The above example will read/write to:
+//
+//	testdata/TestExampleMyStructDoValue.golden
+//
+// DoValueP() does the same using the Codec registered for the file extension
+// of the given name.
+//
+// As methods cannot have type parameters, DoValueWith() and DoValuePWith() take
+// the *Golden instance to use as their first argument:
+//
+//	want := golden.DoValueWith(g, t, got)
+//
+// # Directories
+//
+// DoDir() handles whole directory trees, like the output of code generators.
+// When updating, all files within the given directory are mirrored into a
+// golden directory named after t.Name() with a ".dir" suffix. Otherwise every
+// file is compared against the golden directory, reporting each file which
+// differs, is missing, or is unexpected:
+//
+//	func TestGenerate(t *testing.T) {
+//		out := t.TempDir()
+//		generate(out)
+//
+//		golden.DoDir(t, out)
+//	}
+//
+// The above example will read/write all files within:
+//
+//	testdata/TestGenerate.dir/
+//
+// # HTTP Responses
+//
+// DoHTTPResponse() and DoHTTPRecorder() serialize HTTP responses into a stable
+// text format, with the status line, headers sorted by name, and body:
+//
+//	func TestHandler(t *testing.T) {
+//		rec := httptest.NewRecorder()
+//		handler(rec, httptest.NewRequest("GET", "/", nil))
+//
+//		golden.DoHTTPRecorder(t, rec)
+//	}
+//
+// The status line, headers, and body are compared separately, and each which
+// does not match is reported with its own diff. Headers which change on every
+// request, like "Date", are left out by setting IgnoreHeaders.
+//
+// # Commands
+//
+// DoCmd() runs a *exec.Cmd, and compares its stdout, stderr, and exit code
+// against separate golden files, reporting a diff for each which does not
+// match:
+//
+//	func TestCLI(t *testing.T) {
+//		golden.DoCmd(t, exec.Command("mycli", "--help"))
+//	}
+//
+// The above example will read/write to:
+//
+//	testdata/TestCLI/stdout.golden
+//	testdata/TestCLI/stderr.golden
+//	testdata/TestCLI/exitcode.golden
+//
+// # Writers
+//
+// Writer() and WriterP() return an io.Writer for code which writes its output
+// to one, like loggers and renderers. When the test finishes, everything
+// written is compared against the golden file, just like Assert() does:
+//
+//	func TestRender(t *testing.T) {
+//		render(golden.Writer(t), &MyStruct{Foo: "Bar"})
+//	}
+//
+// # End-of-Test Verification
+//
+// When TestingT instances implement CleanupT, like *testing.T does, additional
+// checks can be performed when each test finishes. With VerifyGets enabled,
+// golden files read with Get(), Do(), or their "P" suffixed variants, which
+// were never compared with Assert() or any of its variants, are reported.
+//
+// Expect() declares the names of golden files a test is expected to use, and
+// reports any of them which were not:
+//
+//	func TestExampleMyStructP(t *testing.T) {
+//		golden.Expect(t, "json", "xml")
+//
+//		golden.AssertP(t, "json", gotJSON)
+//		golden.AssertP(t, "xml", gotXML)
+//	}
+//
+// # Recording HTTP Exchanges
+//
+// Transport() returns a http.RoundTripper which records outbound HTTP
+// exchanges into a cassette golden file when updating, and replays them from
+// it otherwise, failing the test on any unexpected request:
+//
+//	func TestFetch(t *testing.T) {
+//		client := &http.Client{Transport: golden.Transport(t, nil)}
+//
+//		resp, err := client.Get("https://example.com/")
+//		// ...
+//	}
+//
+// The above example will read/write to:
+//
+//	testdata/TestFetch/cassette.golden
+//
+// # Txtar Archives
+//
+// Tests with many named golden files can store them all in a single txtar
+// archive per test instead, by setting Storage to StorageTxtar:
+//
+//	g := golden.New(golden.WithStorage(golden.StorageTxtar))
+//
+// With the TestExampleMyStructP example above, both the "json" and "xml"
+// golden files are then stored as sections of:
+//
+//	testdata/TestExampleMyStructP.txtar
+//
+// Golden files without a name, as used by Get(), Set() and Do(), are still
+// stored as separate files. Data read from a txtar archive always ends with a
+// newline, and data containing txtar file marker lines cannot be stored.
+//
+// # Scrubbing Volatile Content
+//
+// Timestamps, UUIDs, temporary paths and similar values which change on every
+// run can be replaced with stable placeholders by Scrubbers, before data is
+// written to golden files, and before it is compared by Assert() and friends:
+//
+//	g := golden.New(golden.WithScrubbers(
+//		golden.TimestampScrubber(),
+//		golden.UUIDScrubber(),
+//		golden.TempDirScrubber(),
+//	))
+//
+// Each distinct value is replaced with a numbered placeholder, like
+// "<UUID-1>" and "<UUID-2>", so golden files still show which values are the
+// same. As Do() returns golden file content, use Scrub() on the actual data
+// before comparing it yourself.
+//
+// # Normalization
+//
+// Golden files checked out on Windows may have CRLF line endings, causing
+// byte-for-byte comparisons to fail. With Normalization set, line endings,
+// trailing whitespace, and the trailing newline are normalized when reading,
+// writing, and comparing golden files:
+//
+//	g := golden.New(golden.WithNormalization(golden.NormalizeAll))
+//
+// To change options for a single call, use With() to get a modified copy of
+// the Default or any other *Golden instance:
+//
+//	golden.With(golden.WithNormalization(golden.NormalizeLineEndings)).
+//		Assert(t, got)
+//
+// # Reading and Writing Without Failing
+//
+// Get(), Set() and friends fail the test with t.Fatalf() when something goes
+// wrong. To instead record the failure with t.Errorf() and let the test
+// continue, create a custom *Golden instance with FailMode set to FailError:
+//
+//	g := golden.New(golden.WithFailMode(golden.FailError))
+//
+// Where failing the test is not desirable at all, for example in setup helpers
+// or to fall back to other data, Read(), ReadP(), Write(), and WriteP() return
+// an error instead. Errors for golden files which do not exist match both
+// ErrNotExist and os.ErrNotExist:
+//
+//	want, err := golden.Read(t)
+//	if errors.Is(err, os.ErrNotExist) {
+//		want = defaultData
+//	}
+//
+// # Orphaned Golden Files
+//
+// Golden files of removed or renamed tests are easily left behind. To detect
+// them, run tests via CheckOrphans() from TestMain():
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(golden.CheckOrphans(m))
+//	}
+//
+// Once all tests have passed, any golden files within Dirname which were not
+// used by any test are reported, causing the test binary to exit with a
+// non-zero status. They are only removed when RemoveOrphans is true. The check
+// is skipped when only a subset of tests is run with the -run flag, with the
+// -short flag, or when any test using golden files was skipped. Golden files of
+// tests excluded by build constraints are reported as orphans.
 package golden
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
+// actualSuffix is appended to the filename of a golden file to get the
+// filename of where actual data is written when ActualFiles is enabled.
+const actualSuffix = ".actual"
+
+// dirSuffix is appended to the name of golden directories used by DoDir().
+const dirSuffix = ".dir"
+
 var (
 	// Default is the default *Golden instance. All package-level functions use
 	// the Default instance.
@@ -142,18 +480,53 @@ var (
 	DefaultDirname = "testdata"
 
 	// DefaultUpdateFunc is the default UpdateFunc value used by New().
-	DefaultUpdateFunc = EnvUpdateFunc
+	DefaultUpdateFunc = EnvOrFlagUpdateFunc
+
+	// DefaultUpdateModeFunc is the default UpdateModeFunc value used by New().
+	DefaultUpdateModeFunc = EnvOrFlagUpdateModeFunc
+
+	// DefaultCIFunc is the default CIFunc value used by New().
+	DefaultCIFunc = EnvCIFunc
+
+	// DefaultFailMode is the default FailMode value used by New().
+	DefaultFailMode = FailFatal
+
+	// DefaultDiffer is the default Differ value used by New(). It produces
+	// unified diffs with 3 lines of context.
+	DefaultDiffer Differ = NewUnifiedDiffer(3)
+
+	// DefaultCodecs is the default set of codecs used by New(), keyed by file
+	// extension. New() copies the map, so changes to it only affect *Golden
+	// instances created afterwards.
+	DefaultCodecs = map[string]Codec{
+		".json": &JSONCodec{},
+		".xml":  &XMLCodec{},
+	}
+
+	// DefaultIgnoreHeaders is the default IgnoreHeaders value used by New().
+	DefaultIgnoreHeaders = []string{"Date"}
 )
 
-// Do is a convenience function for calling Update(), Set(), and Get() in a
-// single call. If Update() returns true, data will be written to the golden
-// file using Set(), before reading it back with Get().
+// Do is a convenience function for calling UpdateTest(), Set(), and Get() in a
+// single call. If UpdateTest() returns true, data will be written to the golden
+// file using Set(), before reading it back with Get(). When UpdateMode() is
+// UpdateMissing, data is only written if the golden file does not exist.
 func Do(t TestingT, data []byte) []byte {
 	t.Helper()
 
 	return Default.Do(t, data)
 }
 
+// Assert is a convenience function for calling Do() and comparing the result
+// against got. If the golden file content does not match got, the test is
+// marked as failed with t.Errorf() detailing the golden file and the mismatch.
+// Returns true if the golden file content matches got.
+func Assert(t TestingT, got []byte) bool {
+	t.Helper()
+
+	return Default.Assert(t, got)
+}
+
 // File returns the filename of the golden file for the given *testing.T
 // instance as determined by t.Name().
 func File(t TestingT) string {
@@ -164,7 +537,7 @@ func File(t TestingT) string {
 
 // Get returns the content of the golden file for the given *testing.T instance
 // as determined by t.Name(). If no golden file can be found/read, it will fail
-// the test by calling t.Fatal().
+// the test by calling t.Fatal(), or t.Error() when FailMode is FailError.
 func Get(t TestingT) []byte {
 	t.Helper()
 
@@ -173,22 +546,56 @@ func Get(t TestingT) []byte {
 
 // Set writes given data to the golden file for the given *testing.T instance as
 // determined by t.Name(). If writing fails it will fail the test by calling
-// t.Fatal() with error details.
+// t.Fatal(), or t.Error() when FailMode is FailError, with error details.
 func Set(t TestingT, data []byte) {
 	t.Helper()
 
 	Default.Set(t, data)
 }
 
-// DoP is a convenience function for calling Update(), SetP(), and GetP() in a
-// single call. If Update() returns true, data will be written to the golden
-// file using SetP(), before reading it back with GetP().
+// Read returns the content of the golden file for the given *testing.T
+// instance as determined by t.Name(). Unlike Get(), it does not fail the test,
+// but instead returns an error if the golden file cannot be read. If the golden
+// file does not exist, the error matches ErrNotExist and os.ErrNotExist.
+func Read(t TestingT) ([]byte, error) {
+	t.Helper()
+
+	return Default.Read(t)
+}
+
+// Write writes given data to the golden file for the given *testing.T instance
+// as determined by t.Name(). Unlike Set(), it does not fail the test, but
+// instead returns an error if writing fails.
+func Write(t TestingT, data []byte) error {
+	t.Helper()
+
+	return Default.Write(t, data)
+}
+
+// DoP is a convenience function for calling UpdateTest(), SetP(), and GetP() in
+// a single call. If UpdateTest() returns true, data will be written to the
+// golden file using SetP(), before reading it back with GetP(). When
+// UpdateMode() is UpdateMissing, data is only written if the golden file does
+// not exist.
 func DoP(t TestingT, name string, data []byte) []byte {
 	t.Helper()
 
 	return Default.DoP(t, name, data)
 }
 
+// AssertP is a convenience function for calling DoP() and comparing the result
+// against got. If the golden file content does not match got, the test is
+// marked as failed with t.Errorf() detailing the golden file and the mismatch.
+// Returns true if the golden file content matches got.
+//
+// This is very similar to Assert(), but it allows multiple different golden
+// files to be used within the same one *testing.T instance.
+func AssertP(t TestingT, name string, got []byte) bool {
+	t.Helper()
+
+	return Default.AssertP(t, name, got)
+}
+
 // FileP returns the filename of the specifically named golden file for the
 // given *testing.T instance as determined by t.Name().
 func FileP(t TestingT, name string) string {
@@ -199,7 +606,8 @@ func FileP(t TestingT, name string) string {
 
 // GetP returns the content of the specifically named golden file belonging
 // to the given *testing.T instance as determined by t.Name(). If no golden file
-// can be found/read, it will fail the test with t.Fatal().
+// can be found/read, it will fail the test with t.Fatal(), or t.Error() when
+// FailMode is FailError.
 //
 // This is very similar to Get(), but it allows multiple different golden files
 // to be used within the same one *testing.T instance.
@@ -211,7 +619,8 @@ func GetP(t TestingT, name string) []byte {
 
 // SetP writes given data of the specifically named golden file belonging to
 // the given *testing.T instance as determined by t.Name(). If writing fails it
-// will fail the test with t.Fatal() detailing the error.
+// will fail the test with t.Fatal(), or t.Error() when FailMode is FailError,
+// detailing the error.
 //
 // This is very similar to Set(), but it allows multiple different golden files
 // to be used within the same one *testing.T instance.
@@ -221,16 +630,59 @@ func SetP(t TestingT, name string, data []byte) {
 	Default.SetP(t, name, data)
 }
 
-// Update returns true when golden is set to update golden files. Should be used
-// to determine if golden.Set() or golden.SetP() should be called or not.
+// ReadP returns the content of the specifically named golden file belonging
+// to the given *testing.T instance as determined by t.Name(). Unlike GetP(), it
+// does not fail the test, but instead returns an error if the golden file
+// cannot be read. If the golden file does not exist, the error matches
+// ErrNotExist and os.ErrNotExist.
 //
-// Default behavior uses EnvUpdateFunc() to check if the "GOLDEN_UPDATE"
-// environment variable is set to a truthy value. To customize create a custom
-// *Golden instance with New() and set a new UpdateFunc value.
+// This is very similar to Read(), but it allows multiple different golden
+// files to be used within the same one *testing.T instance.
+func ReadP(t TestingT, name string) ([]byte, error) {
+	t.Helper()
+
+	return Default.ReadP(t, name)
+}
+
+// WriteP writes given data to the specifically named golden file belonging to
+// the given *testing.T instance as determined by t.Name(). Unlike SetP(), it
+// does not fail the test, but instead returns an error if writing fails.
+//
+// This is very similar to Write(), but it allows multiple different golden
+// files to be used within the same one *testing.T instance.
+func WriteP(t TestingT, name string, data []byte) error {
+	t.Helper()
+
+	return Default.WriteP(t, name, data)
+}
+
+// Update returns true when golden is set to update golden files. It does not
+// consider GOLDEN_UPDATE_RUN or the update mode, so use UpdateTest() to
+// determine if golden.Set() or golden.SetP() should be called or not.
+//
+// Default behavior uses EnvOrFlagUpdateFunc() to check if the "GOLDEN_UPDATE"
+// environment variable or the -golden.update flag is set to a truthy value. To
+// customize create a custom *Golden instance with New() and set a new
+// UpdateFunc value.
 func Update() bool {
 	return Default.Update()
 }
 
+// UpdateTest returns true when the golden file belonging to the given TestingT
+// instance and optional name should be updated. It is used by Do(), DoP(),
+// Assert() and AssertP() to determine if golden files should be written.
+//
+// Default behavior returns true when Update() returns true, and the golden
+// file matches the "GOLDEN_UPDATE_RUN" environment variable as checked by
+// EnvUpdateRunFunc(), and the -golden.run flag as checked by
+// FlagUpdateRunFunc(). To customize create a custom *Golden instance with New()
+// and set a new UpdateTestFunc value.
+func UpdateTest(t TestingT, name string) bool {
+	t.Helper()
+
+	return Default.UpdateTest(t, name)
+}
+
 // Golden handles all interactions with golden files. The top-level package
 // functions all just proxy through to a default global *Golden instance.
 type Golden struct {
@@ -254,17 +706,103 @@ type Golden struct {
 	// UpdateFunc is used to determine if golden files should be updated or
 	// not. Its boolean return value is returned by Update().
 	UpdateFunc UpdateFunc
+
+	// UpdateTestFunc is used to determine if the golden file for a specific
+	// test and name should be updated. Its boolean return value is returned by
+	// UpdateTest(). If nil, UpdateTest() uses UpdateFunc, restricted by
+	// EnvUpdateRunFunc() and FlagUpdateRunFunc().
+	UpdateTestFunc UpdateTestFunc
+
+	// UpdateModeFunc is used to determine which golden files are written when
+	// Update() returns true. Its return value is returned by UpdateMode(). If
+	// nil, UpdateAll is used.
+	UpdateModeFunc UpdateModeFunc
+
+	// FailMode determines how tests are failed when golden files cannot be
+	// found, read, or written. Mismatches reported by Assert() and AssertP()
+	// always use t.Errorf().
+	FailMode FailMode
+
+	// Differ is used to describe the differences between golden file content
+	// and actual data when a comparison fails. If nil, DefaultDiffer is used.
+	Differ Differ
+
+	// CIFunc is used to determine if tests are running in CI. Its boolean
+	// return value is returned by CI(). If nil, CI is never detected.
+	CIFunc CIFunc
+
+	// AllowCIUpdate allows golden files to be written when CI() returns true.
+	// By default any attempt to write a golden file in CI fails the test.
+	AllowCIUpdate bool
+
+	// ActualFiles determines if the actual data should be written to a
+	// ".actual" file next to the golden file when a comparison fails, for
+	// example "testdata/TestFoo.golden.actual". Stale ".actual" files are
+	// removed when a comparison succeeds.
+	ActualFiles bool
+
+	// Codecs holds the codecs used by DoValueP(), DoValueBytes() and
+	// AssertValue(), keyed by file extension including the leading dot, like
+	// ".json".
+	Codecs map[string]Codec
+
+	// Scrubbers are applied in order to replace volatile content with stable
+	// placeholders, before data is written to a golden file, and before it is
+	// compared against golden file content.
+	Scrubbers []Scrubber
+
+	// Normalization determines how line endings and trailing whitespace are
+	// normalized when reading, writing, and comparing golden files. By
+	// default no normalization is performed.
+	Normalization Normalization
+
+	// Tolerance determines how much numbers may differ when comparing
+	// structured golden files, like with AssertJSON(), while still being
+	// considered equal. By default numbers must be exactly equal.
+	Tolerance Tolerance
+
+	// IgnorePaths holds JSON paths of values which are not compared by
+	// AssertJSON() and friends, like "$.meta.createdAt". See
+	// CompareOptions.IgnorePaths for the supported syntax.
+	IgnorePaths []string
+
+	// Storage determines how golden files are stored on disk. By default each
+	// golden file is stored as a separate file.
+	Storage Storage
+
+	// IgnoreHeaders holds the names of HTTP headers which are left out when
+	// serializing responses with DoHTTPResponse() and DoHTTPRecorder(). Names
+	// are matched case-insensitively.
+	IgnoreHeaders []string
+
+	// RemoveOrphans determines if CheckOrphans() removes orphaned golden
+	// files, instead of only reporting them. Golden files of tests which did
+	// not run, like those excluded by build constraints, are removed too.
+	RemoveOrphans bool
+
+	// VerifyGets determines if golden files read with Get(), GetP(), Do(), or
+	// DoP() must also be compared with Assert() or any of its variants before
+	// the test finishes. Golden files which were read but never compared are
+	// reported with t.Errorf(). It requires TestingT instances to implement
+	// CleanupT.
+	VerifyGets bool
 }
 
 // New returns a new *Golden instance with default values correctly populated.
 // It accepts zero or more Option functions that can modify the default values.
 func New(opts ...Option) *Golden {
 	g := &Golden{
-		DirMode:    DefaultDirMode,
-		FileMode:   DefaultFileMode,
-		Suffix:     DefaultSuffix,
-		Dirname:    DefaultDirname,
-		UpdateFunc: DefaultUpdateFunc,
+		DirMode:        DefaultDirMode,
+		FileMode:       DefaultFileMode,
+		Suffix:         DefaultSuffix,
+		Dirname:        DefaultDirname,
+		UpdateFunc:     DefaultUpdateFunc,
+		UpdateModeFunc: DefaultUpdateModeFunc,
+		CIFunc:         DefaultCIFunc,
+		FailMode:       DefaultFailMode,
+		Differ:         DefaultDiffer,
+		Codecs:         cloneCodecs(DefaultCodecs),
+		IgnoreHeaders:  append([]string(nil), DefaultIgnoreHeaders...),
 	}
 
 	for _, opt := range opts {
@@ -274,17 +812,49 @@ func New(opts ...Option) *Golden {
 	return g
 }
 
-// Do is a convenience function for calling Update(), Set(), and Get() in a
-// single call. If Update() returns true, data will be written to the golden
-// file using Set(), before reading it back with Get().
+// With returns a copy of the Default *Golden instance with the given options
+// applied, allowing options to be changed for a single call:
+//
+//	golden.With(golden.WithNormalization(golden.NormalizeAll)).Assert(t, got)
+func With(opts ...Option) *Golden {
+	return Default.With(opts...)
+}
+
+// With returns a copy of s with the given options applied. s itself is not
+// modified.
+func (s *Golden) With(opts ...Option) *Golden {
+	c := *s
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return &c
+}
+
+// Do is a convenience function for calling UpdateTest(), Set(), and Get() in a
+// single call. If UpdateTest() returns true, data will be written to the golden
+// file using Set(), before reading it back with Get(). When UpdateMode() is
+// UpdateMissing, data is only written if the golden file does not exist.
 func (s *Golden) Do(t TestingT, data []byte) []byte {
 	t.Helper()
 
-	if s.Update() {
-		s.Set(t, data)
+	b, ok := s.do(t, "", data)
+	if ok {
+		s.trackRead(t, "")
 	}
 
-	return s.Get(t)
+	return b
+}
+
+// Assert is a convenience function for calling Do() and comparing the result
+// against got. If the golden file content does not match got, the test is
+// marked as failed with t.Errorf() detailing the golden file and the mismatch.
+// Returns true if the golden file content matches got.
+func (s *Golden) Assert(t TestingT, got []byte) bool {
+	t.Helper()
+
+	return s.assert(t, "", got)
 }
 
 // File returns the filename of the golden file for the given *testing.T
@@ -297,37 +867,85 @@ func (s *Golden) File(t TestingT) string {
 
 // Get returns the content of the golden file for the given *testing.T instance
 // as determined by t.Name(). If no golden file can be found/read, it will fail
-// the test by calling t.Fatal().
+// the test by calling t.Fatal(), or t.Error() when FailMode is FailError.
 func (s *Golden) Get(t TestingT) []byte {
 	t.Helper()
 
-	return s.get(t, "")
+	b, ok := s.get(t, "")
+	if ok {
+		s.trackRead(t, "")
+	}
+
+	return b
 }
 
 // Set writes given data to the golden file for the given *testing.T instance as
 // determined by t.Name(). If writing fails it will fail the test by calling
-// t.Fatal() with error details.
+// t.Fatal(), or t.Error() when FailMode is FailError, with error details.
 func (s *Golden) Set(t TestingT, data []byte) {
 	t.Helper()
 
 	s.set(t, "", data)
 }
 
-// DoP is a convenience function for calling Update(), SetP(), and GetP() in a
-// single call. If Update() returns true, data will be written to the golden
-// file using SetP(), before reading it back with GetP().
+// Read returns the content of the golden file for the given *testing.T
+// instance as determined by t.Name(). Unlike Get(), it does not fail the test,
+// but instead returns an error if the golden file cannot be read. If the golden
+// file does not exist, the error matches ErrNotExist and os.ErrNotExist.
+func (s *Golden) Read(t TestingT) ([]byte, error) {
+	t.Helper()
+
+	return s.read(t, "")
+}
+
+// Write writes given data to the golden file for the given *testing.T instance
+// as determined by t.Name(). Unlike Set(), it does not fail the test, but
+// instead returns an error if writing fails.
+func (s *Golden) Write(t TestingT, data []byte) error {
+	t.Helper()
+
+	return s.write(t, "", data)
+}
+
+// DoP is a convenience function for calling UpdateTest(), SetP(), and GetP() in
+// a single call. If UpdateTest() returns true, data will be written to the
+// golden file using SetP(), before reading it back with GetP(). When
+// UpdateMode() is UpdateMissing, data is only written if the golden file does
+// not exist.
 func (s *Golden) DoP(t TestingT, name string, data []byte) []byte {
 	t.Helper()
 
 	if name == "" {
-		t.Fatalf("golden: name cannot be empty")
+		s.fail(t, "golden: name cannot be empty")
+
+		return nil
 	}
 
-	if s.Update() {
-		s.SetP(t, name, data)
+	b, ok := s.do(t, name, data)
+	if ok {
+		s.trackRead(t, name)
 	}
 
-	return s.GetP(t, name)
+	return b
+}
+
+// AssertP is a convenience function for calling DoP() and comparing the result
+// against got. If the golden file content does not match got, the test is
+// marked as failed with t.Errorf() detailing the golden file and the mismatch.
+// Returns true if the golden file content matches got.
+//
+// This is very similar to Assert(), but it allows multiple different golden
+// files to be used within the same one *testing.T instance.
+func (s *Golden) AssertP(t TestingT, name string, got []byte) bool {
+	t.Helper()
+
+	if name == "" {
+		s.fail(t, "golden: name cannot be empty")
+
+		return false
+	}
+
+	return s.assert(t, name, got)
 }
 
 // FileP returns the filename of the specifically named golden file for the
@@ -336,7 +954,9 @@ func (s *Golden) FileP(t TestingT, name string) string {
 	t.Helper()
 
 	if name == "" {
-		t.Fatalf("golden: name cannot be empty")
+		s.fail(t, "golden: name cannot be empty")
+
+		return ""
 	}
 
 	return s.file(t, name)
@@ -344,7 +964,8 @@ func (s *Golden) FileP(t TestingT, name string) string {
 
 // GetP returns the content of the specifically named golden file belonging
 // to the given *testing.T instance as determined by t.Name(). If no golden file
-// can be found/read, it will fail the test with t.Fatal().
+// can be found/read, it will fail the test with t.Fatal(), or t.Error() when
+// FailMode is FailError.
 //
 // This is very similar to Get(), but it allows multiple different golden files
 // to be used within the same one *testing.T instance.
@@ -352,15 +973,23 @@ func (s *Golden) GetP(t TestingT, name string) []byte {
 	t.Helper()
 
 	if name == "" {
-		t.Fatalf("golden: name cannot be empty")
+		s.fail(t, "golden: name cannot be empty")
+
+		return nil
 	}
 
-	return s.get(t, name)
+	b, ok := s.get(t, name)
+	if ok {
+		s.trackRead(t, name)
+	}
+
+	return b
 }
 
 // SetP writes given data of the specifically named golden file belonging to
 // the given *testing.T instance as determined by t.Name(). If writing fails it
-// will fail the test with t.Fatal() detailing the error.
+// will fail the test with t.Fatal(), or t.Error() when FailMode is FailError,
+// detailing the error.
 //
 // This is very similar to Set(), but it allows multiple different golden files
 // to be used within the same one *testing.T instance.
@@ -368,67 +997,410 @@ func (s *Golden) SetP(t TestingT, name string, data []byte) {
 	t.Helper()
 
 	if name == "" {
-		t.Fatalf("golden: name cannot be empty")
+		s.fail(t, "golden: name cannot be empty")
+
+		return
 	}
 
 	s.set(t, name, data)
 }
 
-// Update returns true when golden is set to update golden files. Should be used
-// to determine if golden.Set() or golden.SetP() should be called or not.
+// ReadP returns the content of the specifically named golden file belonging
+// to the given *testing.T instance as determined by t.Name(). Unlike GetP(), it
+// does not fail the test, but instead returns an error if the golden file
+// cannot be read. If the golden file does not exist, the error matches
+// ErrNotExist and os.ErrNotExist.
+//
+// This is very similar to Read(), but it allows multiple different golden
+// files to be used within the same one *testing.T instance.
+func (s *Golden) ReadP(t TestingT, name string) ([]byte, error) {
+	t.Helper()
+
+	if name == "" {
+		return nil, fmt.Errorf("golden: %w", ErrEmptyName)
+	}
+
+	return s.read(t, name)
+}
+
+// WriteP writes given data to the specifically named golden file belonging to
+// the given *testing.T instance as determined by t.Name(). Unlike SetP(), it
+// does not fail the test, but instead returns an error if writing fails.
 //
-// Default behavior uses EnvUpdateFunc() to check if the "GOLDEN_UPDATE"
-// environment variable is set to a truthy value. To customize set a new
-// UpdateFunc value on *Golden.
+// This is very similar to Write(), but it allows multiple different golden
+// files to be used within the same one *testing.T instance.
+func (s *Golden) WriteP(t TestingT, name string, data []byte) error {
+	t.Helper()
+
+	if name == "" {
+		return fmt.Errorf("golden: %w", ErrEmptyName)
+	}
+
+	return s.write(t, name, data)
+}
+
+// Update returns true when golden is set to update golden files. It does not
+// consider GOLDEN_UPDATE_RUN or the update mode, so use UpdateTest() to
+// determine if golden.Set() or golden.SetP() should be called or not.
+//
+// Default behavior uses EnvOrFlagUpdateFunc() to check if the "GOLDEN_UPDATE"
+// environment variable or the -golden.update flag is set to a truthy value. To
+// customize set a new UpdateFunc value on *Golden.
 func (s *Golden) Update() bool {
 	return s.UpdateFunc()
 }
 
+// UpdateTest returns true when the golden file belonging to the given TestingT
+// instance and optional name should be updated. It is used by Do(), DoP(),
+// Assert() and AssertP() to determine if golden files should be written.
+//
+// If UpdateTestFunc is set, its return value is returned. Otherwise UpdateFunc
+// is used via AdaptUpdateFunc(), and restricted to golden files matching the
+// "GOLDEN_UPDATE_RUN" environment variable as checked by EnvUpdateRunFunc(),
+// and the -golden.run flag as checked by FlagUpdateRunFunc(). If either regular
+// expression is invalid, the test is failed according to FailMode, and false is
+// returned.
+func (s *Golden) UpdateTest(t TestingT, name string) bool {
+	t.Helper()
+
+	if s.UpdateTestFunc != nil {
+		return s.UpdateTestFunc(t, name)
+	}
+
+	if !AdaptUpdateFunc(s.UpdateFunc)(t, name) {
+		return false
+	}
+
+	ok, err := envUpdateRun(t, name)
+	if err == nil && ok {
+		ok, err = flagUpdateRun(t, name)
+	}
+	if err != nil {
+		s.fail(t, "%s", err.Error())
+
+		return false
+	}
+
+	return ok
+}
+
+// CI returns true when tests are running in CI, in which case golden files are
+// not allowed to be written unless AllowCIUpdate is true.
+//
+// Default behavior uses EnvCIFunc() to check if the "CI" environment variable
+// is set to a truthy value. To customize set a new CIFunc value on *Golden.
+func (s *Golden) CI() bool {
+	if s.CIFunc == nil {
+		return false
+	}
+
+	return s.CIFunc()
+}
+
+// UpdateMode returns the update mode, which determines which golden files are
+// written when Update() returns true.
+//
+// Default behavior uses EnvOrFlagUpdateModeFunc() to check if the
+// -golden.update flag, or the "GOLDEN_UPDATE" environment variable when the
+// flag is not enabled, is set to "missing". To customize set a new
+// UpdateModeFunc value on *Golden.
+func (s *Golden) UpdateMode() UpdateMode {
+	if s.UpdateModeFunc == nil {
+		return UpdateAll
+	}
+
+	return s.UpdateModeFunc()
+}
+
 func (s *Golden) file(t TestingT, name string) string {
+	t.Helper()
+
+	f, err := s.path(t, name)
+	if err != nil {
+		s.fail(t, "%s", err.Error())
+	}
+
+	return f
+}
+
+func (s *Golden) path(t TestingT, name string) (string, error) {
+	f, err := s.resolve(t, name)
+	if err != nil {
+		return "", err
+	}
+
+	touch(f)
+	observe(t)
+	trackUse(t, s.label(f, name))
+
+	return f, nil
+}
+
+// resolve returns the path of the named golden file, without recording it as
+// used like path() does.
+func (s *Golden) resolve(t TestingT, name string) (string, error) {
 	if t.Name() == "" {
-		t.Fatalf("golden: could not determine filename")
+		return "", fmt.Errorf("golden: %w", ErrNoTestName)
 	}
 
 	base := []string{s.Dirname, filepath.FromSlash(t.Name())}
-	if name != "" {
+	suffix := s.Suffix
+	if s.txtar(name) {
+		suffix = txtarSuffix
+	} else if name != "" {
 		base = append(base, name)
 	}
 
-	f := filepath.Clean(filepath.Join(base...) + s.Suffix)
+	return sanitizePath(filepath.Clean(filepath.Join(base...) + suffix)), nil
+}
 
-	dirty := strings.Split(f, string(os.PathSeparator))
-	clean := make([]string, 0, len(dirty))
-	for _, s := range dirty {
-		clean = append(clean, sanitizeFilename(s))
+// dirPath returns the directory holding golden files for the given TestingT
+// instance, as used by DoDir(). It has a dirSuffix, so it never collides with
+// the directory holding named golden files and the golden files of sub-tests.
+func (s *Golden) dirPath(t TestingT) (string, error) {
+	if t.Name() == "" {
+		return "", fmt.Errorf("golden: %w", ErrNoTestName)
 	}
 
-	return strings.Join(clean, string(os.PathSeparator))
+	return sanitizePath(filepath.Clean(
+		filepath.Join(s.Dirname, filepath.FromSlash(t.Name())) + dirSuffix,
+	)), nil
 }
 
-func (s *Golden) get(t TestingT, name string) []byte {
-	f := s.file(t, name)
+// do performs the update-or-read cycle of Do() and DoP(). The returned boolean
+// is false if reading or writing the golden file failed.
+func (s *Golden) do(t TestingT, name string, data []byte) ([]byte, bool) {
+	t.Helper()
 
-	b, err := os.ReadFile(f)
+	if s.UpdateTest(t, name) &&
+		(s.UpdateMode() != UpdateMissing || !s.exists(t, name)) &&
+		!s.set(t, name, data) {
+		return nil, false
+	}
+
+	return s.get(t, name)
+}
+
+func (s *Golden) exists(t TestingT, name string) bool {
+	f, err := s.path(t, name)
 	if err != nil {
-		t.Fatalf("golden: failed reading %s: %s", f, err.Error())
+		return false
 	}
 
-	return b
+	if s.txtar(name) {
+		_, err = s.readTxtar(f, name)
+
+		return err == nil
+	}
+
+	_, err = os.Stat(f)
+
+	return err == nil
+}
+
+func (s *Golden) assert(t TestingT, name string, got []byte) bool {
+	t.Helper()
+
+	return s.assertWith(t, name, got, s.compareBytes)
 }
 
-func (s *Golden) set(t TestingT, name string, data []byte) {
+// compareFunc compares want against got, returning true if they are
+// considered equal, or false and a description of the differences otherwise.
+type compareFunc func(file string, want, got []byte) (string, bool)
+
+// assertWith is like assert, but uses the given compareFunc to compare the
+// golden file content against got.
+func (s *Golden) assertWith(
+	t TestingT,
+	name string,
+	got []byte,
+	compare compareFunc,
+) bool {
+	t.Helper()
+
+	want, ok := s.do(t, name, got)
+	if !ok {
+		return false
+	}
+
 	f := s.file(t, name)
-	dir := filepath.Dir(f)
+	label := s.label(f, name)
+	trackCompare(t, label)
+
+	got = s.clean(got)
+	if s.txtar(name) {
+		got = fixNL(got)
+	}
+
+	diff, ok := compare(label, want, got)
+	if ok {
+		if s.ActualFiles {
+			s.removeActual(t, f, name)
+		}
+
+		return true
+	}
+
+	t.Errorf("golden: %s does not match:\n%s", label, diff)
+
+	if s.ActualFiles {
+		s.writeActual(t, f, name, got)
+	}
+
+	return false
+}
+
+// compareBytes is a compareFunc which compares want and got byte-for-byte.
+func (s *Golden) compareBytes(file string, want, got []byte) (string, bool) {
+	if bytes.Equal(want, got) {
+		return "", true
+	}
+
+	return s.diff(file, want, got), false
+}
+
+func (s *Golden) writeActual(t TestingT, file, name string, data []byte) {
+	t.Helper()
+
+	f := file + actualSuffix
+	t.Logf("golden: writing .actual file: %s", s.label(f, name))
+
+	if s.txtar(name) {
+		err := s.writeTxtar(f, name, data)
+		if err != nil {
+			t.Errorf("%s", err.Error())
+		}
+
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(f), s.DirMode)
+	if err != nil {
+		t.Errorf("golden: failed to create directory: %s", err.Error())
+
+		return
+	}
+
+	err = os.WriteFile(f, data, s.FileMode)
+	if err != nil {
+		t.Errorf("golden: failed to write file: %s", err.Error())
+	}
+}
+
+func (s *Golden) removeActual(t TestingT, file, name string) {
+	t.Helper()
+
+	f := file + actualSuffix
+
+	var err error
+	if s.txtar(name) {
+		err = s.removeTxtar(f, name)
+	} else {
+		err = os.Remove(f)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		t.Errorf("golden: failed to remove file: %s", err.Error())
+	}
+}
+
+// clean returns data with Scrubbers and Normalization applied.
+func (s *Golden) clean(data []byte) []byte {
+	return s.Normalize(s.Scrub(data))
+}
+
+func (s *Golden) diff(name string, want, got []byte) string {
+	d := s.Differ
+	if d == nil {
+		d = DefaultDiffer
+	}
+
+	return d.Diff(name, "actual", want, got)
+}
+
+// get reads the golden file, failing the test according to FailMode if it
+// cannot be read. The returned boolean is false if reading failed.
+func (s *Golden) get(t TestingT, name string) ([]byte, bool) {
+	t.Helper()
+
+	b, err := s.read(t, name)
+	if err != nil {
+		s.fail(t, "%s", err.Error())
+
+		return nil, false
+	}
+
+	return b, true
+}
+
+// set writes the golden file, failing the test according to FailMode if it
+// cannot be written. Returns false if writing failed.
+func (s *Golden) set(t TestingT, name string, data []byte) bool {
+	t.Helper()
+
+	err := s.write(t, name, data)
+	if err != nil {
+		s.fail(t, "%s", err.Error())
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Golden) read(t TestingT, name string) ([]byte, error) {
+	f, err := s.path(t, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.txtar(name) {
+		b, err := s.readTxtar(f, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.Normalize(b), nil
+	}
+
+	b, err := os.ReadFile(f)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("golden: failed reading %s: %w", f, ErrNotExist)
+	} else if err != nil {
+		return nil, fmt.Errorf("golden: failed reading %s: %w", f, err)
+	}
+
+	return s.Normalize(b), nil
+}
+
+func (s *Golden) write(t TestingT, name string, data []byte) error {
+	f, err := s.path(t, name)
+	if err != nil {
+		return err
+	}
+
+	data = s.clean(data)
+
+	if s.CI() && !s.AllowCIUpdate {
+		return fmt.Errorf("golden: refusing to write %s: %w", f, ErrUpdateInCI)
+	}
+
+	if s.txtar(name) {
+		t.Logf("golden: writing .golden file: %s", s.label(f, name))
+
+		return s.writeTxtar(f, name, data)
+	}
 
 	t.Logf("golden: writing .golden file: %s", f)
 
-	err := os.MkdirAll(dir, s.DirMode)
+	err = os.MkdirAll(filepath.Dir(f), s.DirMode)
 	if err != nil {
-		t.Fatalf("golden: failed to create directory: %s", err.Error())
+		return fmt.Errorf("golden: failed to create directory: %w", err)
 	}
 
 	err = os.WriteFile(f, data, s.FileMode)
 	if err != nil {
-		t.Fatalf("golden: filed to write file: %s", err.Error())
+		return fmt.Errorf("golden: failed to write file: %w", err)
 	}
+
+	return nil
 }
@@ -50,7 +50,16 @@ func TestDefaults(t *testing.T) {
 		assert.Equal(t, DefaultFileMode, Default.FileMode)
 		assert.Equal(t, DefaultSuffix, Default.Suffix)
 		assert.Equal(t, DefaultDirname, Default.Dirname)
-		assertSameFunc(t, EnvUpdateFunc, Default.UpdateFunc)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, Default.UpdateFunc)
+		assert.Nil(t, Default.UpdateTestFunc)
+		assertSameFunc(t, EnvOrFlagUpdateModeFunc, Default.UpdateModeFunc)
+		assertSameFunc(t, EnvCIFunc, Default.CIFunc)
+		assert.False(t, Default.AllowCIUpdate)
+		assert.False(t, Default.RemoveOrphans)
+		assert.Equal(t, DefaultFailMode, Default.FailMode)
+		assert.Equal(t, DefaultDiffer, Default.Differ)
+		assert.Equal(t, DefaultCodecs, Default.Codecs)
+		assert.Equal(t, DefaultIgnoreHeaders, Default.IgnoreHeaders)
 	})
 
 	t.Run("DefaultDirMode", func(t *testing.T) {
@@ -61,6 +70,10 @@ func TestDefaults(t *testing.T) {
 		assert.Equal(t, os.FileMode(0o644), DefaultFileMode)
 	})
 
+	t.Run("DefaultIgnoreHeaders", func(t *testing.T) {
+		assert.Equal(t, []string{"Date"}, DefaultIgnoreHeaders)
+	})
+
 	t.Run("DefaultSuffix", func(t *testing.T) {
 		assert.Equal(t, ".golden", DefaultSuffix)
 	})
@@ -70,7 +83,30 @@ func TestDefaults(t *testing.T) {
 	})
 
 	t.Run("DefaultUpdateFunc", func(t *testing.T) {
-		assertSameFunc(t, EnvUpdateFunc, DefaultUpdateFunc)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, DefaultUpdateFunc)
+	})
+
+	t.Run("DefaultUpdateModeFunc", func(t *testing.T) {
+		assertSameFunc(t, EnvOrFlagUpdateModeFunc, DefaultUpdateModeFunc)
+	})
+
+	t.Run("DefaultCIFunc", func(t *testing.T) {
+		assertSameFunc(t, EnvCIFunc, DefaultCIFunc)
+	})
+
+	t.Run("DefaultFailMode", func(t *testing.T) {
+		assert.Equal(t, FailFatal, DefaultFailMode)
+	})
+
+	t.Run("DefaultDiffer", func(t *testing.T) {
+		assert.Equal(t, &UnifiedDiffer{Context: 3}, DefaultDiffer)
+	})
+
+	t.Run("DefaultCodecs", func(t *testing.T) {
+		assert.Equal(t,
+			map[string]Codec{".json": &JSONCodec{}, ".xml": &XMLCodec{}},
+			DefaultCodecs,
+		)
 	})
 
 	t.Run("customized Default* variables", func(t *testing.T) {
@@ -80,6 +116,11 @@ func TestDefaults(t *testing.T) {
 		defaultSuffix := DefaultSuffix
 		defaultDirname := DefaultDirname
 		defaultUpdateFunc := DefaultUpdateFunc
+		defaultUpdateModeFunc := DefaultUpdateModeFunc
+		defaultCIFunc := DefaultCIFunc
+		defaultFailMode := DefaultFailMode
+		defaultDiffer := DefaultDiffer
+		defaultCodecs := DefaultCodecs
 
 		// Restore the default values after the test.
 		t.Cleanup(func() {
@@ -88,6 +129,11 @@ func TestDefaults(t *testing.T) {
 			DefaultSuffix = defaultSuffix
 			DefaultDirname = defaultDirname
 			DefaultUpdateFunc = defaultUpdateFunc
+			DefaultUpdateModeFunc = defaultUpdateModeFunc
+			DefaultCIFunc = defaultCIFunc
+			DefaultFailMode = defaultFailMode
+			DefaultDiffer = defaultDiffer
+			DefaultCodecs = defaultCodecs
 		})
 
 		// Set all the default values to new values.
@@ -99,6 +145,20 @@ func TestDefaults(t *testing.T) {
 		updateFunc := func() bool { return true }
 		DefaultUpdateFunc = updateFunc
 
+		updateModeFunc := func() UpdateMode { return UpdateMissing }
+		DefaultUpdateModeFunc = updateModeFunc
+
+		ciFunc := func() bool { return true }
+		DefaultCIFunc = ciFunc
+
+		DefaultFailMode = FailError
+
+		differ := &UnifiedDiffer{Context: 1}
+		DefaultDiffer = differ
+
+		codec := &JSONCodec{}
+		DefaultCodecs = map[string]Codec{".js": codec}
+
 		// Create a new Golden instance with the new values.
 		got := New()
 
@@ -107,6 +167,16 @@ func TestDefaults(t *testing.T) {
 		assert.Equal(t, DefaultSuffix, got.Suffix)
 		assert.Equal(t, DefaultDirname, got.Dirname)
 		assertSameFunc(t, updateFunc, got.UpdateFunc)
+		assertSameFunc(t, updateModeFunc, got.UpdateModeFunc)
+		assertSameFunc(t, ciFunc, got.CIFunc)
+		assert.Equal(t, FailError, got.FailMode)
+		assert.Same(t, differ, got.Differ)
+		assert.Equal(t, map[string]Codec{".js": codec}, got.Codecs)
+		assert.Same(t, codec, got.Codecs[".js"])
+
+		// The codecs map is copied, not shared.
+		got.Codecs[".xml"] = &XMLCodec{}
+		assert.NotContains(t, DefaultCodecs, ".xml")
 	})
 }
 
@@ -117,7 +187,7 @@ func TestNew(t *testing.T) {
 		assert.Equal(t, DefaultFileMode, g.FileMode)
 		assert.Equal(t, DefaultSuffix, g.Suffix)
 		assert.Equal(t, DefaultDirname, g.Dirname)
-		assertSameFunc(t, EnvUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
 	})
 
 	// Test each option individually
@@ -128,7 +198,7 @@ func TestNew(t *testing.T) {
 		assert.Equal(t, DefaultFileMode, g.FileMode)
 		assert.Equal(t, DefaultSuffix, g.Suffix)
 		assert.Equal(t, DefaultDirname, g.Dirname)
-		assertSameFunc(t, EnvUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
 	})
 
 	t.Run("WithFileMode", func(t *testing.T) {
@@ -138,7 +208,7 @@ func TestNew(t *testing.T) {
 		assert.Equal(t, customMode, g.FileMode)
 		assert.Equal(t, DefaultSuffix, g.Suffix)
 		assert.Equal(t, DefaultDirname, g.Dirname)
-		assertSameFunc(t, EnvUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
 	})
 
 	t.Run("WithSuffix", func(t *testing.T) {
@@ -148,7 +218,7 @@ func TestNew(t *testing.T) {
 		assert.Equal(t, DefaultFileMode, g.FileMode)
 		assert.Equal(t, customSuffix, g.Suffix)
 		assert.Equal(t, DefaultDirname, g.Dirname)
-		assertSameFunc(t, EnvUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
 	})
 
 	t.Run("WithDirname", func(t *testing.T) {
@@ -158,7 +228,7 @@ func TestNew(t *testing.T) {
 		assert.Equal(t, DefaultFileMode, g.FileMode)
 		assert.Equal(t, DefaultSuffix, g.Suffix)
 		assert.Equal(t, customDirname, g.Dirname)
-		assertSameFunc(t, EnvUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
 	})
 
 	t.Run("WithUpdateFunc", func(t *testing.T) {
@@ -171,6 +241,175 @@ func TestNew(t *testing.T) {
 		assertSameFunc(t, customUpdateFunc, g.UpdateFunc)
 	})
 
+	t.Run("WithUpdateTestFunc", func(t *testing.T) {
+		customUpdateTestFunc := func(TestingT, string) bool { return true }
+		g := New(WithUpdateTestFunc(customUpdateTestFunc))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, customUpdateTestFunc, g.UpdateTestFunc)
+	})
+
+	t.Run("WithUpdateMode", func(t *testing.T) {
+		g := New(WithUpdateMode(UpdateMissing))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, UpdateMissing, g.UpdateMode())
+	})
+
+	t.Run("WithCIFunc", func(t *testing.T) {
+		customCIFunc := func() bool { return true }
+		g := New(WithCIFunc(customCIFunc))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, customCIFunc, g.CIFunc)
+		assert.False(t, g.AllowCIUpdate)
+	})
+
+	t.Run("WithAllowCIUpdate", func(t *testing.T) {
+		g := New(WithAllowCIUpdate(true))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assertSameFunc(t, EnvCIFunc, g.CIFunc)
+		assert.True(t, g.AllowCIUpdate)
+	})
+
+	t.Run("WithFailMode", func(t *testing.T) {
+		g := New(WithFailMode(FailError))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, FailError, g.FailMode)
+	})
+
+	t.Run("WithDiffer", func(t *testing.T) {
+		customDiffer := &UnifiedDiffer{Context: 10}
+		g := New(WithDiffer(customDiffer))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Same(t, customDiffer, g.Differ)
+	})
+
+	t.Run("WithActualFiles", func(t *testing.T) {
+		g := New(WithActualFiles(true))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.True(t, g.ActualFiles)
+	})
+
+	t.Run("WithCodec", func(t *testing.T) {
+		customCodec := &XMLCodec{}
+		g := New(WithCodec(".svg", customCodec))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Same(t, customCodec, g.Codecs[".svg"])
+		assert.Same(t, DefaultCodecs[".json"], g.Codecs[".json"])
+		assert.NotContains(t, DefaultCodecs, ".svg")
+	})
+
+	t.Run("WithScrubbers", func(t *testing.T) {
+		scrubber := UUIDScrubber()
+		g := New(WithScrubbers(scrubber))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, []Scrubber{scrubber}, g.Scrubbers)
+	})
+
+	t.Run("WithNormalization", func(t *testing.T) {
+		g := New(WithNormalization(NormalizeLineEndings))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, NormalizeLineEndings, g.Normalization)
+	})
+
+	t.Run("WithTolerance", func(t *testing.T) {
+		g := New(WithTolerance(0.1, 0.2))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, Tolerance{Abs: 0.1, Rel: 0.2}, g.Tolerance)
+	})
+
+	t.Run("IgnorePaths", func(t *testing.T) {
+		g := New(IgnorePaths("$.id"))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, []string{"$.id"}, g.IgnorePaths)
+	})
+
+	t.Run("WithStorage", func(t *testing.T) {
+		g := New(WithStorage(StorageTxtar))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, StorageTxtar, g.Storage)
+	})
+
+	t.Run("WithIgnoreHeaders", func(t *testing.T) {
+		g := New(WithIgnoreHeaders("Date", "X-Request-Id"))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.Equal(t, []string{"Date", "X-Request-Id"}, g.IgnoreHeaders)
+	})
+
+	t.Run("WithRemoveOrphans", func(t *testing.T) {
+		g := New(WithRemoveOrphans(true))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.True(t, g.RemoveOrphans)
+	})
+
+	t.Run("WithVerifyGets", func(t *testing.T) {
+		g := New(WithVerifyGets(true))
+		assert.Equal(t, DefaultDirMode, g.DirMode)
+		assert.Equal(t, DefaultFileMode, g.FileMode)
+		assert.Equal(t, DefaultSuffix, g.Suffix)
+		assert.Equal(t, DefaultDirname, g.Dirname)
+		assertSameFunc(t, EnvOrFlagUpdateFunc, g.UpdateFunc)
+		assert.True(t, g.VerifyGets)
+	})
+
 	// Test multiple options at once
 	t.Run("MultipleOptions", func(t *testing.T) {
 		customDirMode := os.FileMode(0o700)
@@ -195,7 +434,30 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestGolden_With(t *testing.T) {
+	g := New(WithSuffix(".orig"))
+
+	got := g.With(WithSuffix(".new"), WithDirname("fixtures"))
+
+	assert.NotSame(t, g, got)
+	assert.Equal(t, ".new", got.Suffix)
+	assert.Equal(t, "fixtures", got.Dirname)
+	assert.Equal(t, ".orig", g.Suffix)
+	assert.Equal(t, DefaultDirname, g.Dirname)
+}
+
+func TestWith(t *testing.T) {
+	got := With(WithSuffix(".new"))
+
+	assert.NotSame(t, Default, got)
+	assert.Equal(t, ".new", got.Suffix)
+	assert.Equal(t, DefaultSuffix, Default.Suffix)
+}
+
 func TestDo(t *testing.T) {
+	// Golden files are not allowed to be written in CI.
+	t.Setenv("CI", "")
+
 	t.Cleanup(func() {
 		err := os.RemoveAll(filepath.Join("testdata", "TestDo"))
 		require.NoError(t, err)
@@ -291,6 +553,274 @@ func TestDo(t *testing.T) {
 	}
 }
 
+func TestDo_UpdateMissing(t *testing.T) {
+	t.Setenv("CI", "")
+
+	dir := t.TempDir()
+	g := New(
+		WithDirname(dir),
+		WithUpdateFunc(func() bool { return true }),
+		WithUpdateMode(UpdateMissing),
+	)
+
+	// Golden file does not exist, so it is created.
+	content := []byte("This is the golden file for TestDo_UpdateMissing")
+	got := g.Do(t, content)
+	assert.Equal(t, content, got)
+
+	fileContent, err := os.ReadFile(g.File(t))
+	require.NoError(t, err)
+	assert.Equal(t, content, fileContent)
+
+	// Golden file exists, so it is left untouched.
+	got = g.Do(t, []byte("This should not be written"))
+	assert.Equal(t, content, got)
+
+	fileContent, err = os.ReadFile(g.File(t))
+	require.NoError(t, err)
+	assert.Equal(t, content, fileContent)
+
+	// Same behavior applies to named golden files.
+	named := []byte("This is the named golden file")
+	got = g.DoP(t, "named", named)
+	assert.Equal(t, named, got)
+
+	got = g.DoP(t, "named", []byte("This should not be written"))
+	assert.Equal(t, named, got)
+
+	fileContent, err = os.ReadFile(g.FileP(t, "named"))
+	require.NoError(t, err)
+	assert.Equal(t, named, fileContent)
+}
+
+func TestDoP_UpdateRun(t *testing.T) {
+	t.Setenv("CI", "")
+	t.Setenv("GOLDEN_UPDATE_RUN", "TestDoP_UpdateRun/.*/json$")
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	)
+
+	for _, name := range []string{"json", "xml"} {
+		f := g.FileP(t, name)
+		err := os.MkdirAll(filepath.Dir(f), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(f, []byte("old "+name), 0o600)
+		require.NoError(t, err)
+	}
+
+	t.Run("sub", func(t *testing.T) {
+		for _, name := range []string{"json", "xml"} {
+			f := g.FileP(t, name)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte("old "+name), 0o600)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []byte("new json"),
+			g.DoP(t, "json", []byte("new json")),
+		)
+		assert.Equal(t, []byte("old xml"),
+			g.DoP(t, "xml", []byte("new xml")),
+		)
+	})
+
+	assert.Equal(t, []byte("old json"), g.DoP(t, "json", []byte("new json")))
+	assert.Equal(t, []byte("old xml"), g.DoP(t, "xml", []byte("new xml")))
+}
+
+func TestAssert(t *testing.T) {
+	t.Setenv("CI", "")
+
+	t.Cleanup(func() {
+		err := os.Remove(filepath.Join("testdata", "TestAssert.golden"))
+		require.NoError(t, err)
+	})
+
+	content := []byte("This is the golden file for TestAssert")
+	err := os.MkdirAll("testdata", 0o755)
+	require.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join("testdata", "TestAssert.golden"), content, 0o600,
+	)
+	require.NoError(t, err)
+
+	t.Setenv("GOLDEN_UPDATE", "false")
+	assert.True(t, Assert(t, content))
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     []byte
+		got        []byte
+		want       bool
+		wantFile   []byte
+		wantErrors []string
+	}{
+		{
+			name:     "match",
+			golden:   []byte("hello world"),
+			got:      []byte("hello world"),
+			want:     true,
+			wantFile: []byte("hello world"),
+		},
+		{
+			name:     "mismatch",
+			golden:   []byte("hello world"),
+			got:      []byte("hello mars"),
+			want:     false,
+			wantFile: []byte("hello world"),
+			wantErrors: []string{
+				filepath.Join("TestAssert", "mismatch.golden") +
+					" does not match:\n--- ",
+				"+++ actual\n" +
+					"@@ -1 +1 @@\n" +
+					"-hello world\n" +
+					"\\ No newline at end of file\n" +
+					"+hello mars\n" +
+					"\\ No newline at end of file\n",
+			},
+		},
+		{
+			name:     "mismatch with update",
+			update:   true,
+			golden:   []byte("hello world"),
+			got:      []byte("hello mars"),
+			want:     true,
+			wantFile: []byte("hello mars"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			g := New(
+				WithDirname(dir),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestAssert/" + tt.name)
+
+			f := g.File(ft)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, tt.golden, 0o600)
+			require.NoError(t, err)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.Assert(ft, tt.got) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			b, err := os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, b)
+		})
+	}
+}
+
+func TestAssert_ActualFiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		golden      []byte
+		got         []byte
+		stale       bool
+		wantActual  []byte
+		wantFailure bool
+	}{
+		{
+			name:        "mismatch",
+			enabled:     true,
+			golden:      []byte("hello world"),
+			got:         []byte("hello mars"),
+			wantActual:  []byte("hello mars"),
+			wantFailure: true,
+		},
+		{
+			name:        "mismatch with stale actual file",
+			enabled:     true,
+			golden:      []byte("hello world"),
+			got:         []byte("hello mars"),
+			stale:       true,
+			wantActual:  []byte("hello mars"),
+			wantFailure: true,
+		},
+		{
+			name:    "match",
+			enabled: true,
+			golden:  []byte("hello world"),
+			got:     []byte("hello world"),
+		},
+		{
+			name:    "match with stale actual file",
+			enabled: true,
+			golden:  []byte("hello world"),
+			got:     []byte("hello world"),
+			stale:   true,
+		},
+		{
+			name:        "mismatch when disabled",
+			enabled:     false,
+			golden:      []byte("hello world"),
+			got:         []byte("hello mars"),
+			wantFailure: true,
+		},
+		{
+			name:        "mismatch when disabled with stale actual file",
+			enabled:     false,
+			golden:      []byte("hello world"),
+			got:         []byte("hello mars"),
+			stale:       true,
+			wantActual:  []byte("stale"),
+			wantFailure: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return false }),
+				WithActualFiles(tt.enabled),
+			)
+			ft := newFakeT("TestAssert_ActualFiles/" + tt.name)
+
+			f := g.FileP(ft, "output")
+			actual := f + ".actual"
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, tt.golden, 0o600)
+			require.NoError(t, err)
+			if tt.stale {
+				err = os.WriteFile(actual, []byte("stale"), 0o600)
+				require.NoError(t, err)
+			}
+
+			ft.run(func(ft TestingT) { g.AssertP(ft, "output", tt.got) })
+
+			assert.Equal(t, tt.wantFailure, ft.Failed())
+
+			b, err := os.ReadFile(actual)
+			if tt.wantActual == nil {
+				assert.True(t, os.IsNotExist(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantActual, b)
+			}
+
+			// The golden file itself must never be modified.
+			b, err = os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.golden, b)
+		})
+	}
+}
+
 func TestFile(t *testing.T) {
 	got := File(t)
 
@@ -401,6 +931,8 @@ func TestGet(t *testing.T) {
 }
 
 func TestSet(t *testing.T) {
+	t.Setenv("CI", "")
+
 	t.Cleanup(func() {
 		err := os.RemoveAll(filepath.Join("testdata", "TestSet"))
 		require.NoError(t, err)
@@ -466,7 +998,245 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestSet_CI(t *testing.T) {
+	tests := []struct {
+		name      string
+		ci        bool
+		allow     bool
+		wantFatal bool
+	}{
+		{
+			name: "not in CI",
+			ci:   false,
+		},
+		{
+			name:      "in CI",
+			ci:        true,
+			wantFatal: true,
+		},
+		{
+			name:  "in CI with AllowCIUpdate",
+			ci:    true,
+			allow: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return true }),
+				WithCIFunc(func() bool { return tt.ci }),
+				WithAllowCIUpdate(tt.allow),
+			)
+			content := []byte("hello world")
+
+			for _, fn := range []func(t TestingT){
+				func(t TestingT) { g.Set(t, content) },
+				func(t TestingT) { g.SetP(t, "named", content) },
+				func(t TestingT) { g.Do(t, content) },
+				func(t TestingT) { g.DoP(t, "named", content) },
+				func(t TestingT) { g.Assert(t, content) },
+				func(t TestingT) { g.AssertP(t, "named", content) },
+			} {
+				ft := newFakeT("TestSet_CI/" + tt.name)
+				ft.run(fn)
+
+				if !tt.wantFatal {
+					assert.False(t, ft.Failed())
+
+					continue
+				}
+
+				require.Len(t, ft.fatals, 1)
+				assert.Regexp(t,
+					`^golden: refusing to write .+: golden files must not `+
+						`be updated in CI$`,
+					ft.fatals[0],
+				)
+				assert.NoFileExists(t, g.File(ft))
+				assert.NoFileExists(t, g.FileP(ft, "named"))
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	g := New(WithDirname(t.TempDir()))
+
+	t.Run("existing file", func(t *testing.T) {
+		content := []byte("hello world")
+		err := os.MkdirAll(filepath.Dir(g.File(t)), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(g.File(t), content, 0o600)
+		require.NoError(t, err)
+
+		got, err := g.Read(t)
+
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		got, err := g.Read(t)
+
+		assert.Nil(t, got)
+		assert.EqualError(t, err,
+			"golden: failed reading "+g.File(t)+": golden file does not exist",
+		)
+		assert.ErrorIs(t, err, ErrNotExist)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("unreadable file", func(t *testing.T) {
+		err := os.MkdirAll(g.File(t), 0o755)
+		require.NoError(t, err)
+
+		got, err := g.Read(t)
+
+		assert.Nil(t, got)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotExist)
+	})
+
+	t.Run("empty test name", func(t *testing.T) {
+		ft := newFakeT("")
+
+		got, err := g.Read(ft)
+
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "golden: could not determine filename")
+		assert.ErrorIs(t, err, ErrNoTestName)
+		assert.False(t, ft.Failed())
+	})
+}
+
+func TestReadP(t *testing.T) {
+	g := New(WithDirname(t.TempDir()))
+
+	content := []byte("hello world")
+	err := os.MkdirAll(filepath.Dir(g.FileP(t, "json")), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(g.FileP(t, "json"), content, 0o600)
+	require.NoError(t, err)
+
+	got, err := g.ReadP(t, "json")
+	assert.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	got, err = g.ReadP(t, "xml")
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, ErrNotExist)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	got, err = g.ReadP(t, "")
+	assert.Nil(t, got)
+	assert.EqualError(t, err, "golden: name cannot be empty")
+	assert.ErrorIs(t, err, ErrEmptyName)
+}
+
+func TestWrite(t *testing.T) {
+	t.Setenv("CI", "")
+
+	t.Run("success", func(t *testing.T) {
+		g := New(WithDirname(t.TempDir()))
+		content := []byte("hello world")
+
+		err := g.Write(t, content)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(g.File(t))
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+	})
+
+	t.Run("in CI", func(t *testing.T) {
+		g := New(
+			WithDirname(t.TempDir()),
+			WithCIFunc(func() bool { return true }),
+		)
+
+		err := g.Write(t, []byte("hello world"))
+
+		assert.EqualError(t, err,
+			"golden: refusing to write "+g.File(t)+
+				": golden files must not be updated in CI",
+		)
+		assert.ErrorIs(t, err, ErrUpdateInCI)
+		assert.NoFileExists(t, g.File(t))
+	})
+
+	t.Run("directory creation failure", func(t *testing.T) {
+		dir := t.TempDir()
+		// Create a file where the directory for the golden file should be.
+		err := os.WriteFile(filepath.Join(dir, "TestWrite"), nil, 0o600)
+		require.NoError(t, err)
+		g := New(WithDirname(dir))
+
+		err = g.Write(t, []byte("hello world"))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "golden: failed to create directory: ")
+	})
+
+	t.Run("write failure", func(t *testing.T) {
+		g := New(WithDirname(t.TempDir()))
+		// Create a directory where the golden file should be.
+		err := os.MkdirAll(g.File(t), 0o755)
+		require.NoError(t, err)
+
+		err = g.Write(t, []byte("hello world"))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "golden: failed to write file: ")
+	})
+}
+
+func TestWriteP(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(WithDirname(t.TempDir()))
+	content := []byte("hello world")
+
+	err := g.WriteP(t, "json", content)
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(g.FileP(t, "json"))
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	err = g.WriteP(t, "", content)
+	assert.EqualError(t, err, "golden: name cannot be empty")
+	assert.ErrorIs(t, err, ErrEmptyName)
+}
+
+func TestReadWrite_Default(t *testing.T) {
+	t.Setenv("CI", "")
+	t.Cleanup(func() {
+		err := os.RemoveAll(filepath.Join("testdata", "TestReadWrite_Default"))
+		require.NoError(t, err)
+		err = os.Remove(
+			filepath.Join("testdata", "TestReadWrite_Default.golden"),
+		)
+		require.NoError(t, err)
+	})
+
+	err := Write(t, []byte("default"))
+	require.NoError(t, err)
+	err = WriteP(t, "named", []byte("default named"))
+	require.NoError(t, err)
+
+	got, err := Read(t)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("default"), got)
+
+	got, err = ReadP(t, "named")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("default named"), got)
+}
+
 func TestDoP(t *testing.T) {
+	t.Setenv("CI", "")
+
 	t.Cleanup(func() {
 		err := os.RemoveAll(filepath.Join("testdata", "TestDoP"))
 		require.NoError(t, err)
@@ -557,6 +1327,57 @@ func TestDoP(t *testing.T) {
 	}
 }
 
+func TestAssertP(t *testing.T) {
+	t.Cleanup(func() {
+		err := os.RemoveAll(filepath.Join("testdata", "TestAssertP"))
+		require.NoError(t, err)
+	})
+
+	content := []byte("This is the named golden file for TestAssertP")
+	err := os.MkdirAll(filepath.Join("testdata", "TestAssertP"), 0o755)
+	require.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join("testdata", "TestAssertP", "json.golden"),
+		content, 0o600,
+	)
+	require.NoError(t, err)
+
+	t.Setenv("GOLDEN_UPDATE", "false")
+	assert.True(t, AssertP(t, "json", content))
+
+	t.Run("mismatch", func(t *testing.T) {
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return false }),
+		)
+		ft := newFakeT("TestAssertP/mismatch")
+
+		f := g.FileP(ft, "json")
+		err := os.MkdirAll(filepath.Dir(f), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(f, []byte(`{"foo":"bar"}`), 0o600)
+		require.NoError(t, err)
+
+		var got bool
+		ft.run(func(ft TestingT) {
+			got = g.AssertP(ft, "json", []byte(`{"foo":"baz"}`))
+		})
+
+		assert.False(t, got)
+		assert.Contains(t, ft.Output(), "golden: "+f+" does not match")
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		g := New(WithDirname(t.TempDir()))
+		ft := newFakeT("TestAssertP/empty_name")
+
+		ft.run(func(ft TestingT) { g.AssertP(ft, "", []byte("foo")) })
+
+		assert.Equal(t, []string{"golden: name cannot be empty"}, ft.fatals)
+	})
+}
+
 func TestFileP(t *testing.T) {
 	got := FileP(t, "sub-name")
 	assert.Equal(t,
@@ -714,6 +1535,8 @@ func TestGetP(t *testing.T) {
 }
 
 func TestSetP(t *testing.T) {
+	t.Setenv("CI", "")
+
 	t.Cleanup(func() {
 		err := os.RemoveAll(filepath.Join("testdata", "TestSetP"))
 		require.NoError(t, err)
@@ -801,6 +1624,144 @@ func TestSetP(t *testing.T) {
 	}
 }
 
+func TestGolden_UpdateMode(t *testing.T) {
+	for _, tt := range envUpdateModeFuncTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got := New().UpdateMode()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("nil UpdateModeFunc", func(t *testing.T) {
+		g := &Golden{}
+
+		assert.Equal(t, UpdateAll, g.UpdateMode())
+	})
+}
+
+func TestGolden_UpdateTest(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		updateFunc     UpdateFunc
+		updateTestFunc UpdateTestFunc
+		testName       string
+		named          string
+		want           bool
+		wantErrors     []string
+	}{
+		{
+			name:       "UpdateFunc returns false",
+			updateFunc: func() bool { return false },
+			testName:   "TestFoo",
+			want:       false,
+		},
+		{
+			name:       "UpdateFunc returns true",
+			updateFunc: func() bool { return true },
+			testName:   "TestFoo",
+			want:       true,
+		},
+		{
+			name:       "UpdateFunc returns true with matching run",
+			env:        map[string]string{"GOLDEN_UPDATE_RUN": "Foo/json"},
+			updateFunc: func() bool { return true },
+			testName:   "TestFoo",
+			named:      "json",
+			want:       true,
+		},
+		{
+			name:       "UpdateFunc returns true with non-matching run",
+			env:        map[string]string{"GOLDEN_UPDATE_RUN": "Foo/json"},
+			updateFunc: func() bool { return true },
+			testName:   "TestFoo",
+			named:      "xml",
+			want:       false,
+		},
+		{
+			name:       "UpdateFunc returns false with matching run",
+			env:        map[string]string{"GOLDEN_UPDATE_RUN": "Foo/json"},
+			updateFunc: func() bool { return false },
+			testName:   "TestFoo",
+			named:      "json",
+			want:       false,
+		},
+		{
+			name:       "UpdateTestFunc takes precedence",
+			env:        map[string]string{"GOLDEN_UPDATE_RUN": "Foo/json"},
+			updateFunc: func() bool { return false },
+			updateTestFunc: func(t TestingT, name string) bool {
+				return t.Name() == "TestFoo" && name == "xml"
+			},
+			testName: "TestFoo",
+			named:    "xml",
+			want:     true,
+		},
+		{
+			name:       "invalid run",
+			env:        map[string]string{"GOLDEN_UPDATE_RUN": "Test(Foo"},
+			updateFunc: func() bool { return true },
+			testName:   "TestFoo",
+			want:       false,
+			wantErrors: []string{
+				"golden: invalid GOLDEN_UPDATE_RUN: error parsing " +
+					"regexp: missing closing ): `Test(Foo`",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			g := New(
+				WithUpdateFunc(tt.updateFunc),
+				WithUpdateTestFunc(tt.updateTestFunc),
+				WithFailMode(FailError),
+			)
+			ft := newFakeT(tt.testName)
+
+			got := g.UpdateTest(ft, tt.named)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErrors, ft.errors)
+		})
+	}
+}
+
+func TestGolden_CI(t *testing.T) {
+	for _, tt := range envCIFuncTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got := New().CI()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("nil CIFunc", func(t *testing.T) {
+		g := &Golden{}
+
+		assert.False(t, g.CI())
+	})
+}
+
+func TestUpdateTest(t *testing.T) {
+	t.Setenv("GOLDEN_UPDATE", "1")
+	t.Setenv("GOLDEN_UPDATE_RUN", "^TestUpdateTest/json$")
+
+	assert.True(t, UpdateTest(t, "json"))
+	assert.False(t, UpdateTest(t, "xml"))
+}
+
 func TestUpdate(t *testing.T) {
 	for _, tt := range envUpdateFuncTestCases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,194 @@
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+)
+
+// DoHTTPResponse serializes resp, and compares it against the golden file of
+// the given TestingT instance, after updating the golden file if needed.
+// Returns true if the response matches.
+//
+// This is a wrapper around calling DoHTTPResponse() on the Default *Golden
+// instance.
+func DoHTTPResponse(t TestingT, resp *http.Response) bool {
+	t.Helper()
+
+	return Default.DoHTTPResponse(t, resp)
+}
+
+// DoHTTPRecorder serializes the response recorded by rec, and behaves just
+// like DoHTTPResponse() with it.
+//
+// This is a wrapper around calling DoHTTPRecorder() on the Default *Golden
+// instance.
+func DoHTTPRecorder(t TestingT, rec *httptest.ResponseRecorder) bool {
+	t.Helper()
+
+	return Default.DoHTTPRecorder(t, rec)
+}
+
+// DoHTTPResponse serializes resp into a stable text format, made up of the
+// status line, the headers sorted by name, an empty line, and the body:
+//
+//	HTTP/1.1 200 OK
+//	Content-Type: application/json
+//
+//	{"foo":"bar"}
+//
+// Headers listed in IgnoreHeaders are left out, as are their values. The
+// body of resp is read in full, and replaced so it can be read again by the
+// caller.
+//
+// The serialized response then behaves just like Assert() with the resulting
+// data, except that the status line, headers, and body are compared
+// separately, and each section which does not match is reported on its own.
+// Returns true if the response matches.
+func (s *Golden) DoHTTPResponse(t TestingT, resp *http.Response) bool {
+	t.Helper()
+
+	if resp == nil {
+		s.fail(t, "golden: response cannot be nil")
+
+		return false
+	}
+
+	data, err := s.formatHTTPResponse(resp)
+	if err != nil {
+		s.fail(t, "golden: failed to read response body: %s", err.Error())
+
+		return false
+	}
+
+	return s.assertWith(t, "", data, s.compareHTTPResponse)
+}
+
+// DoHTTPRecorder serializes the response recorded by rec, and behaves just
+// like DoHTTPResponse() with it.
+func (s *Golden) DoHTTPRecorder(
+	t TestingT,
+	rec *httptest.ResponseRecorder,
+) bool {
+	t.Helper()
+
+	if rec == nil {
+		s.fail(t, "golden: response recorder cannot be nil")
+
+		return false
+	}
+
+	return s.DoHTTPResponse(t, rec.Result())
+}
+
+// formatHTTPResponse serializes resp as described by DoHTTPResponse().
+func (s *Golden) formatHTTPResponse(resp *http.Response) ([]byte, error) {
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		body, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	proto := resp.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+
+	status := resp.Status
+	if status == "" {
+		status = fmt.Sprintf(
+			"%d %s", resp.StatusCode, http.StatusText(resp.StatusCode),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(proto + " " + status + "\n")
+
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		if !s.ignoredHeader(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, v := range resp.Header[name] {
+			buf.WriteString(http.CanonicalHeaderKey(name) + ": " + v + "\n")
+		}
+	}
+
+	buf.WriteString("\n")
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// ignoredHeader returns true if the named header is listed in IgnoreHeaders.
+func (s *Golden) ignoredHeader(name string) bool {
+	for _, h := range s.IgnoreHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compareHTTPResponse is a compareFunc which compares the status line,
+// headers, and body of serialized HTTP responses separately.
+func (s *Golden) compareHTTPResponse(
+	file string,
+	want, got []byte,
+) (string, bool) {
+	if bytes.Equal(want, got) {
+		return "", true
+	}
+
+	wantStatus, wantHeaders, wantBody := splitHTTPResponse(want)
+	gotStatus, gotHeaders, gotBody := splitHTTPResponse(got)
+
+	var diffs []string
+	if wantStatus != gotStatus {
+		diffs = append(diffs,
+			fmt.Sprintf("status: want %q, got %q", wantStatus, gotStatus),
+		)
+	}
+	if !bytes.Equal(wantHeaders, gotHeaders) {
+		diffs = append(diffs, "headers:\n"+s.diff(
+			file+" (headers)", wantHeaders, gotHeaders,
+		))
+	}
+	if !bytes.Equal(wantBody, gotBody) {
+		diffs = append(diffs, "body:\n"+s.diff(
+			file+" (body)", wantBody, gotBody,
+		))
+	}
+
+	return strings.Join(diffs, "\n"), false
+}
+
+// splitHTTPResponse splits a serialized HTTP response into its status line,
+// header lines, and body.
+func splitHTTPResponse(data []byte) (string, []byte, []byte) {
+	head, body := data, []byte(nil)
+	if i := bytes.Index(data, []byte("\n\n")); i >= 0 {
+		head, body = data[:i+1], data[i+2:]
+	}
+
+	status, headers := head, []byte(nil)
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		status, headers = head[:i], head[i+1:]
+	}
+
+	return string(status), headers, body
+}
@@ -0,0 +1,291 @@
+package golden
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHTTPHandler(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("X-B", "b")
+		w.Header().Add("X-A", "a2")
+		w.Header().Add("X-A", "a1")
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestGolden_DoHTTPRecorder(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		status     int
+		body       string
+		want       bool
+		wantGolden string
+		wantErrors []string
+	}{
+		{
+			name: "match",
+			golden: "HTTP/1.1 200 OK\nContent-Type: text/plain\n" +
+				"X-A: a2\nX-A: a1\nX-B: b\n\nhello\n",
+			status: http.StatusOK,
+			body:   "hello\n",
+			want:   true,
+		},
+		{
+			name:   "update",
+			update: true,
+			golden: "HTTP/1.1 500 Internal Server Error\n\n",
+			status: http.StatusOK,
+			body:   "hello\n",
+			want:   true,
+			wantGolden: "HTTP/1.1 200 OK\nContent-Type: text/plain\n" +
+				"X-A: a2\nX-A: a1\nX-B: b\n\nhello\n",
+		},
+		{
+			name: "status mismatch",
+			golden: "HTTP/1.1 200 OK\nContent-Type: text/plain\n" +
+				"X-A: a2\nX-A: a1\nX-B: b\n\nhello\n",
+			status: http.StatusNotFound,
+			body:   "hello\n",
+			want:   false,
+			wantErrors: []string{
+				`status: want "HTTP/1.1 200 OK", ` +
+					`got "HTTP/1.1 404 Not Found"`,
+			},
+		},
+		{
+			name: "headers and body mismatch",
+			golden: "HTTP/1.1 200 OK\nContent-Type: text/plain\n" +
+				"X-A: a1\nX-B: b\n\nhello\n",
+			status: http.StatusOK,
+			body:   "world\n",
+			want:   false,
+			wantErrors: []string{
+				"headers_and_body_mismatch.golden does not match:\n" +
+					"headers:\n--- ",
+				"headers_and_body_mismatch.golden (headers)\n+++ actual\n",
+				" Content-Type: text/plain\n+X-A: a2\n X-A: a1\n",
+				"\nbody:\n--- ",
+				"headers_and_body_mismatch.golden (body)\n+++ actual\n" +
+					"@@ -1 +1 @@\n-hello\n+world\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestDoHTTPRecorder/" + tt.name)
+
+			f := g.File(ft)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte(tt.golden), 0o600)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			testHTTPHandler(tt.status, tt.body).ServeHTTP(
+				rec, httptest.NewRequest(http.MethodGet, "/", nil),
+			)
+
+			got := g.DoHTTPRecorder(ft, rec)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed(), ft.Output())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+			assert.NotContains(t, ft.Output(), "status:\n")
+
+			if tt.wantGolden != "" {
+				b, err := os.ReadFile(f)
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantGolden, string(b))
+			}
+		})
+	}
+}
+
+func TestGolden_DoHTTPResponse(t *testing.T) {
+	t.Setenv("CI", "")
+
+	srv := httptest.NewServer(testHTTPHandler(http.StatusCreated, "created"))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+		WithIgnoreHeaders("date", "Content-Length"),
+	)
+	ft := newFakeT("TestDoHTTPResponse")
+
+	assert.True(t, g.DoHTTPResponse(ft, resp))
+	assert.False(t, ft.Failed(), ft.Output())
+
+	b, err := os.ReadFile(g.File(ft))
+	require.NoError(t, err)
+	assert.Equal(t,
+		"HTTP/1.1 201 Created\nContent-Type: text/plain\n"+
+			"X-A: a2\nX-A: a1\nX-B: b\n\ncreated",
+		string(b),
+	)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "created", string(body))
+}
+
+func TestGolden_DoHTTPResponse_Constructed(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+		WithIgnoreHeaders(),
+	)
+	ft := newFakeT("TestDoHTTPResponse_Constructed")
+
+	resp := &http.Response{
+		StatusCode: http.StatusTeapot,
+		Header:     http.Header{"Date": {"today"}},
+	}
+
+	assert.True(t, g.DoHTTPResponse(ft, resp))
+	assert.False(t, ft.Failed(), ft.Output())
+
+	b, err := os.ReadFile(g.File(ft))
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 418 I'm a teapot\nDate: today\n\n", string(b))
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestGolden_DoHTTPResponse_Errors(t *testing.T) {
+	g := New(WithDirname(t.TempDir()))
+
+	tests := []struct {
+		name string
+		fn   func(ft TestingT) bool
+		want string
+	}{
+		{
+			name: "nil response",
+			fn: func(ft TestingT) bool {
+				return g.DoHTTPResponse(ft, nil)
+			},
+			want: "golden: response cannot be nil",
+		},
+		{
+			name: "nil recorder",
+			fn: func(ft TestingT) bool {
+				return g.DoHTTPRecorder(ft, nil)
+			},
+			want: "golden: response recorder cannot be nil",
+		},
+		{
+			name: "body read error",
+			fn: func(ft TestingT) bool {
+				return g.DoHTTPResponse(ft, &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(errReader{}),
+				})
+			},
+			want: "golden: failed to read response body: read failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeT("TestDoHTTPResponse_Errors")
+
+			var got bool
+			ft.run(func(ft TestingT) { got = tt.fn(ft) })
+
+			assert.False(t, got)
+			assert.Equal(t, []string{tt.want}, ft.fatals)
+		})
+	}
+}
+
+func TestSplitHTTPResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantStatus  string
+		wantHeaders string
+		wantBody    string
+	}{
+		{
+			name:        "full",
+			data:        "HTTP/1.1 200 OK\nA: 1\nB: 2\n\nbody\n\nmore",
+			wantStatus:  "HTTP/1.1 200 OK",
+			wantHeaders: "A: 1\nB: 2\n",
+			wantBody:    "body\n\nmore",
+		},
+		{
+			name:       "no headers",
+			data:       "HTTP/1.1 200 OK\n\nbody",
+			wantStatus: "HTTP/1.1 200 OK",
+			wantBody:   "body",
+		},
+		{
+			name:       "status only",
+			data:       "HTTP/1.1 200 OK",
+			wantStatus: "HTTP/1.1 200 OK",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, headers, body := splitHTTPResponse([]byte(tt.data))
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantHeaders, string(headers))
+			assert.Equal(t, tt.wantBody, string(body))
+		})
+	}
+}
+
+func TestDoHTTPRecorder(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+	ft := newFakeT("TestDoHTTPRecorder")
+
+	rec := httptest.NewRecorder()
+	testHTTPHandler(http.StatusOK, "ok").ServeHTTP(
+		rec, httptest.NewRequest(http.MethodGet, "/", nil),
+	)
+
+	assert.True(t, DoHTTPRecorder(ft, rec))
+	assert.False(t, ft.Failed(), ft.Output())
+	assert.NotContains(t, string(Get(ft)), "Date:")
+
+	assert.True(t, DoHTTPResponse(ft, rec.Result()))
+	assert.False(t, ft.Failed(), ft.Output())
+}
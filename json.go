@@ -0,0 +1,457 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DoJSON marshals v as indented JSON, and then behaves just like Do() with the
+// resulting data. It returns the content of the golden file.
+//
+// This is a wrapper around calling DoJSON() on the Default *Golden instance.
+func DoJSON(t TestingT, v interface{}) []byte {
+	t.Helper()
+
+	return Default.DoJSON(t, v)
+}
+
+// DoJSONP marshals v as indented JSON, and then behaves just like DoP() with
+// the resulting data. It returns the content of the golden file.
+//
+// This is a wrapper around calling DoJSONP() on the Default *Golden instance.
+func DoJSONP(t TestingT, name string, v interface{}) []byte {
+	t.Helper()
+
+	return Default.DoJSONP(t, name, v)
+}
+
+// AssertJSON marshals v as indented JSON, and then behaves just like Assert()
+// with the resulting data, except that the golden file content is compared
+// semantically instead of byte-for-byte.
+//
+// This is a wrapper around calling AssertJSON() on the Default *Golden
+// instance.
+func AssertJSON(t TestingT, v interface{}) bool {
+	t.Helper()
+
+	return Default.AssertJSON(t, v)
+}
+
+// AssertJSONP marshals v as indented JSON, and then behaves just like
+// AssertP() with the resulting data, except that the golden file content is
+// compared semantically instead of byte-for-byte.
+//
+// This is a wrapper around calling AssertJSONP() on the Default *Golden
+// instance.
+func AssertJSONP(t TestingT, name string, v interface{}) bool {
+	t.Helper()
+
+	return Default.AssertJSONP(t, name, v)
+}
+
+// DoJSON marshals v as JSON indented with two spaces and a trailing newline,
+// and then behaves just like Do() with the resulting data. It returns the
+// content of the golden file.
+func (s *Golden) DoJSON(t TestingT, v interface{}) []byte {
+	t.Helper()
+
+	data, ok := s.marshalJSON(t, v)
+	if !ok {
+		return nil
+	}
+
+	b, _ := s.do(t, "", data)
+
+	return b
+}
+
+// DoJSONP marshals v as JSON indented with two spaces and a trailing newline,
+// and then behaves just like DoP() with the resulting data. It returns the
+// content of the golden file.
+func (s *Golden) DoJSONP(t TestingT, name string, v interface{}) []byte {
+	t.Helper()
+
+	if name == "" {
+		s.fail(t, "golden: name cannot be empty")
+
+		return nil
+	}
+
+	data, ok := s.marshalJSON(t, v)
+	if !ok {
+		return nil
+	}
+
+	b, _ := s.do(t, name, data)
+
+	return b
+}
+
+// AssertJSON marshals v as JSON indented with two spaces and a trailing
+// newline, and then behaves just like Assert() with the resulting data.
+//
+// Instead of comparing byte-for-byte, both the golden file content and the
+// marshaled value are decoded and compared semantically, so differences in key
+// order and whitespace are ignored. On mismatch, each difference is reported
+// by its JSON path, for example:
+//
+//	$.items[1].name: want "foo", got "bar"
+//
+// Numbers are compared within the Tolerance of s, if any. Numbers outside of
+// the tolerance are reported along with their difference:
+//
+//	$.items[1].score: want 0.5, got 0.6 (delta 0.1)
+//
+// Values at any of the IgnorePaths of s are not compared, but are still
+// written to the golden file when updating.
+func (s *Golden) AssertJSON(t TestingT, v interface{}) bool {
+	t.Helper()
+
+	data, ok := s.marshalJSON(t, v)
+	if !ok {
+		return false
+	}
+
+	return s.assertWith(t, "", data, compareJSONWith(s.compareOptions()))
+}
+
+// AssertJSONP marshals v as JSON indented with two spaces and a trailing
+// newline, and then behaves just like AssertP() with the resulting data. The
+// golden file content is compared just like AssertJSON() does.
+func (s *Golden) AssertJSONP(t TestingT, name string, v interface{}) bool {
+	t.Helper()
+
+	if name == "" {
+		s.fail(t, "golden: name cannot be empty")
+
+		return false
+	}
+
+	data, ok := s.marshalJSON(t, v)
+	if !ok {
+		return false
+	}
+
+	return s.assertWith(t, name, data, compareJSONWith(s.compareOptions()))
+}
+
+// JSONCodec is a Codec for JSON golden files. Values are stored indented with
+// two spaces, and compared semantically, reporting differences by JSON path.
+type JSONCodec struct{}
+
+var _ Codec = (*JSONCodec)(nil)
+
+// Marshal encodes v as JSON.
+func (c *JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON data into v.
+func (c *JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Normalize returns data indented with two spaces, followed by a trailing
+// newline. Key order and number formatting are preserved.
+func (c *JSONCodec) Normalize(data []byte) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+var _ CompareCodec = (*JSONCodec)(nil)
+
+// Diff semantically compares want and got, returning each difference by its
+// JSON path.
+func (c *JSONCodec) Diff(want, got []byte) string {
+	return c.DiffOptions(want, got, CompareOptions{})
+}
+
+// DiffOptions semantically compares want and got just like Diff, comparing
+// numbers within the tolerance given by opts, and ignoring values at any of
+// its IgnorePaths.
+func (c *JSONCodec) DiffOptions(
+	want, got []byte,
+	opts CompareOptions,
+) string {
+	diff, _ := compareJSONWith(opts)("", want, got)
+
+	return diff
+}
+
+func (s *Golden) marshalJSON(t TestingT, v interface{}) ([]byte, bool) {
+	t.Helper()
+
+	data, err := marshalJSON(v)
+	if err != nil {
+		s.fail(t, "golden: failed to marshal JSON: %s", err.Error())
+
+		return nil, false
+	}
+
+	return data, true
+}
+
+// marshalJSON marshals v as JSON indented with two spaces, followed by a
+// trailing newline.
+func marshalJSON(v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// unmarshalJSON decodes data into generic JSON values, keeping numbers as
+// json.Number to avoid losing precision.
+func unmarshalJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+
+	return v, nil
+}
+
+// compareJSON is a compareFunc which decodes and semantically compares want
+// and got as JSON.
+func compareJSON(file string, want, got []byte) (string, bool) {
+	return compareJSONWith(CompareOptions{})(file, want, got)
+}
+
+// compareJSONWith returns a compareFunc which decodes and semantically
+// compares want and got as JSON according to opts.
+func compareJSONWith(opts CompareOptions) compareFunc {
+	return func(_ string, want, got []byte) (string, bool) {
+		return diffJSON(want, got, opts)
+	}
+}
+
+func diffJSON(want, got []byte, opts CompareOptions) (string, bool) {
+	wantV, err := unmarshalJSON(want)
+	if err != nil {
+		return fmt.Sprintf("invalid JSON in golden file: %s\n", err), false
+	}
+
+	gotV, err := unmarshalJSON(got)
+	if err != nil {
+		return fmt.Sprintf("invalid JSON in actual: %s\n", err), false
+	}
+
+	ignore, err := compileJSONPaths(opts.IgnorePaths)
+	if err != nil {
+		return fmt.Sprintf("%s\n", err), false
+	}
+
+	c := &jsonComparer{tolerance: opts.Tolerance, ignore: ignore}
+	c.compare("$", wantV, gotV)
+
+	if len(c.diffs) == 0 {
+		return "", true
+	}
+
+	return strings.Join(c.diffs, "\n") + "\n", false
+}
+
+// jsonComparer semantically compares decoded JSON values, recording each
+// difference by its JSON path.
+type jsonComparer struct {
+	tolerance Tolerance
+	ignore    []*regexp.Regexp
+	diffs     []string
+}
+
+// ignored returns true if path matches any of the ignored JSON paths.
+func (c *jsonComparer) ignored(path string) bool {
+	for _, re := range c.ignore {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *jsonComparer) compare(path string, want, got interface{}) {
+	if c.ignored(path) {
+		return
+	}
+
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		for _, k := range sortedKeys(w, g) {
+			p := jsonPathKey(path, k)
+			wv, wok := w[k]
+			gv, gok := g[k]
+
+			switch {
+			case !gok:
+				c.missing(p, wv)
+			case !wok:
+				c.unexpected(p, gv)
+			default:
+				c.compare(p, wv, gv)
+			}
+		}
+
+		return
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(w) || i < len(g); i++ {
+			p := path + "[" + strconv.Itoa(i) + "]"
+
+			switch {
+			case i >= len(g):
+				c.missing(p, w[i])
+			case i >= len(w):
+				c.unexpected(p, g[i])
+			default:
+				c.compare(p, w[i], g[i])
+			}
+		}
+
+		return
+	case json.Number:
+		g, ok := got.(json.Number)
+		if !ok {
+			break
+		}
+
+		if equalJSONNumbers(w, g) {
+			return
+		}
+		if c.tolerance.IsZero() {
+			break
+		}
+
+		wf, werr := w.Float64()
+		gf, gerr := g.Float64()
+		if werr != nil || gerr != nil {
+			break
+		}
+
+		if c.tolerance.Equal(wf, gf) {
+			return
+		}
+
+		c.diffs = append(c.diffs, fmt.Sprintf(
+			"%s: want %s, got %s (delta %s)", path, w, g,
+			strconv.FormatFloat(math.Abs(gf-wf), 'g', -1, 64),
+		))
+
+		return
+	default:
+		if want == got {
+			return
+		}
+	}
+
+	c.diffs = append(c.diffs, fmt.Sprintf(
+		"%s: want %s, got %s", path, jsonString(want), jsonString(got),
+	))
+}
+
+func (c *jsonComparer) missing(path string, want interface{}) {
+	if c.ignored(path) {
+		return
+	}
+
+	c.diffs = append(c.diffs,
+		fmt.Sprintf("%s: missing, want %s", path, jsonString(want)),
+	)
+}
+
+func (c *jsonComparer) unexpected(path string, got interface{}) {
+	if c.ignored(path) {
+		return
+	}
+
+	c.diffs = append(c.diffs,
+		fmt.Sprintf("%s: unexpected, got %s", path, jsonString(got)),
+	)
+}
+
+// equalJSONNumbers returns true if a and b represent the same number, even
+// when formatted differently, like "1" and "1.0".
+func equalJSONNumbers(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+
+	ar, aok := new(big.Rat).SetString(a.String())
+	br, bok := new(big.Rat).SetString(b.String())
+
+	return aok && bok && ar.Cmp(br) == 0
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+var jsonIdentRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPathKey appends key k to the JSON path, using dot notation for simple
+// keys, and bracket notation otherwise.
+func jsonPathKey(path, k string) string {
+	if jsonIdentRegexp.MatchString(k) {
+		return path + "." + k
+	}
+
+	return path + "[" + strconv.Quote(k) + "]"
+}
+
+// jsonString returns the compact JSON representation of v.
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(b)
+}
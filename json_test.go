@@ -0,0 +1,459 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonTestStruct struct {
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Count float64  `json:"count"`
+}
+
+func TestDoJSON(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		v          interface{}
+		want       string
+		wantFatals []string
+	}{
+		{
+			name:   "read",
+			golden: `{"name":"foo"}`,
+			v:      &jsonTestStruct{Name: "bar"},
+			want:   `{"name":"foo"}`,
+		},
+		{
+			name:   "update",
+			update: true,
+			golden: `{"name":"foo"}`,
+			v:      &jsonTestStruct{Name: "bar", Tags: []string{"a"}},
+			want: "{\n" +
+				"  \"name\": \"bar\",\n" +
+				"  \"tags\": [\n" +
+				"    \"a\"\n" +
+				"  ],\n" +
+				"  \"count\": 0\n" +
+				"}\n",
+		},
+		{
+			name:   "marshal error",
+			update: true,
+			golden: "foo",
+			v:      make(chan int),
+			want:   "",
+			wantFatals: []string{
+				"golden: failed to marshal JSON: " +
+					"json: unsupported type: chan int",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestDoJSON/" + tt.name)
+
+			for _, name := range []string{"", "json"} {
+				f := g.FileP(ft, "json")
+				if name == "" {
+					f = g.File(ft)
+				}
+				err := os.MkdirAll(filepath.Dir(f), 0o755)
+				require.NoError(t, err)
+				err = os.WriteFile(f, []byte(tt.golden), 0o600)
+				require.NoError(t, err)
+			}
+
+			var got, gotP []byte
+			ft.run(func(ft TestingT) { got = g.DoJSON(ft, tt.v) })
+			ft.run(func(ft TestingT) { gotP = g.DoJSONP(ft, "json", tt.v) })
+
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.want, string(gotP))
+			assert.Equal(t, append(tt.wantFatals, tt.wantFatals...), ft.fatals)
+		})
+	}
+}
+
+func TestDoJSONP_EmptyName(t *testing.T) {
+	ft := newFakeT("TestDoJSONP_EmptyName")
+
+	var got []byte
+	ft.run(func(ft TestingT) { got = DoJSONP(ft, "", "foo") })
+
+	assert.Nil(t, got)
+	assert.Equal(t, []string{"golden: name cannot be empty"}, ft.fatals)
+}
+
+func TestAssertJSON(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		v          interface{}
+		want       bool
+		wantFile   string
+		wantErrors []string
+	}{
+		{
+			name: "semantically equal",
+			golden: `{"tags":["a","b"],"count":1.50,` +
+				`"name":"foo"}`,
+			v: &jsonTestStruct{
+				Name: "foo", Tags: []string{"a", "b"}, Count: 1.5,
+			},
+			want: true,
+			wantFile: `{"tags":["a","b"],"count":1.50,` +
+				`"name":"foo"}`,
+		},
+		{
+			name:   "mismatch",
+			golden: `{"name":"foo","tags":["a","b"],"count":1,"x":{}}`,
+			v: &jsonTestStruct{
+				Name: "bar", Tags: []string{"a"}, Count: 2,
+			},
+			want:     false,
+			wantFile: `{"name":"foo","tags":["a","b"],"count":1,"x":{}}`,
+			wantErrors: []string{
+				filepath.Join("TestAssertJSON", "mismatch.golden") +
+					" does not match:\n" +
+					"$.count: want 1, got 2\n" +
+					"$.name: want \"foo\", got \"bar\"\n" +
+					"$.tags[1]: missing, want \"b\"\n" +
+					"$.x: missing, want {}\n",
+			},
+		},
+		{
+			name:     "invalid golden file",
+			golden:   `{"name":`,
+			v:        &jsonTestStruct{Name: "foo"},
+			want:     false,
+			wantFile: `{"name":`,
+			wantErrors: []string{
+				"invalid_golden_file.golden does not match:\n" +
+					"invalid JSON in golden file: unexpected EOF\n",
+			},
+		},
+		{
+			name:   "mismatch with update",
+			update: true,
+			golden: `{"name":"foo"}`,
+			v:      map[string]interface{}{"name": "bar"},
+			want:   true,
+			wantFile: "{\n" +
+				"  \"name\": \"bar\"\n" +
+				"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestAssertJSON/" + tt.name)
+
+			f := g.File(ft)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte(tt.golden), 0o600)
+			require.NoError(t, err)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.AssertJSON(ft, tt.v) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			b, err := os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, string(b))
+		})
+	}
+}
+
+func TestAssertJSONP(t *testing.T) {
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return false }),
+	)
+	ft := newFakeT("TestAssertJSONP")
+
+	f := g.FileP(ft, "resp")
+	err := os.MkdirAll(filepath.Dir(f), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(f, []byte(`{"name": "foo"}`), 0o600)
+	require.NoError(t, err)
+
+	assert.True(t, g.AssertJSONP(ft, "resp", map[string]string{"name": "foo"}))
+	assert.False(t, ft.Failed())
+
+	var got bool
+	ft.run(func(ft TestingT) { got = g.AssertJSONP(ft, "", "foo") })
+
+	assert.False(t, got)
+	assert.Equal(t, []string{"golden: name cannot be empty"}, ft.fatals)
+}
+
+func TestCompareJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{
+			name: "equal",
+			want: `{"a": [1, 2, {"b": null}], "c": true}`,
+			got:  `{"c":true,"a":[1,2,{"b":null}]}`,
+		},
+		{
+			name: "equal numbers",
+			want: `[1, 1.0, 1e2, 12345678901234567890]`,
+			got:  `[1.0, 1, 100, 12345678901234567890.0]`,
+		},
+		{
+			name: "large numbers",
+			want: `[12345678901234567890]`,
+			got:  `[12345678901234567891]`,
+			diff: "$[0]: want 12345678901234567890, " +
+				"got 12345678901234567891\n",
+		},
+		{
+			name: "type mismatch",
+			want: `{"a": {"b": 1}}`,
+			got:  `{"a": [1]}`,
+			diff: "$.a: want {\"b\":1}, got [1]\n",
+		},
+		{
+			name: "unexpected",
+			want: `{"a": [1]}`,
+			got:  `{"a": [1, 2], "b": "x"}`,
+			diff: "$.a[1]: unexpected, got 2\n" +
+				"$.b: unexpected, got \"x\"\n",
+		},
+		{
+			name: "special keys",
+			want: `{"foo bar": {"a.b": 1, "_x1": 2}}`,
+			got:  `{"foo bar": {"a.b": 2, "_x1": 3}}`,
+			diff: "$[\"foo bar\"]._x1: want 2, got 3\n" +
+				"$[\"foo bar\"][\"a.b\"]: want 1, got 2\n",
+		},
+		{
+			name: "invalid actual",
+			want: `{}`,
+			got:  `{} {}`,
+			diff: "invalid JSON in actual: " +
+				"invalid character after top-level value\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, ok := compareJSON("want", []byte(tt.want), []byte(tt.got))
+
+			assert.Equal(t, tt.diff == "", ok)
+			assert.Equal(t, tt.diff, diff)
+		})
+	}
+}
+
+func TestCompareJSONWith_Tolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance Tolerance
+		want      string
+		got       string
+		diff      string
+	}{
+		{
+			name: "no tolerance",
+			want: `{"a": 1.0}`,
+			got:  `{"a": 1.0000000001}`,
+			diff: "$.a: want 1.0, got 1.0000000001\n",
+		},
+		{
+			name:      "within absolute tolerance",
+			tolerance: Tolerance{Abs: 1e-9},
+			want:      `{"a": 1.0, "b": [-2.5]}`,
+			got:       `{"a": 1.0000000001, "b": [-2.5000000001]}`,
+		},
+		{
+			name:      "within relative tolerance",
+			tolerance: Tolerance{Rel: 1e-6},
+			want:      `[1000000, 0.001]`,
+			got:       `[1000000.5, 0.0010000009]`,
+		},
+		{
+			name:      "outside tolerance",
+			tolerance: Tolerance{Abs: 0.01, Rel: 0.01},
+			want:      `{"a": 1, "b": 10}`,
+			got:       `{"a": 1.5, "b": 10.05}`,
+			diff:      "$.a: want 1, got 1.5 (delta 0.5)\n",
+		},
+		{
+			name:      "equal numbers out of float64 range",
+			tolerance: Tolerance{Abs: 1e-9, Rel: 1e-9},
+			want:      `{"a": 1e400, "b": 1E400}`,
+			got:       `{"a": 1e400, "b": 10e399}`,
+		},
+		{
+			name:      "different numbers out of float64 range",
+			tolerance: Tolerance{Rel: 1e-9},
+			want:      `{"a": 1e400}`,
+			got:       `{"a": 2e400}`,
+			diff:      "$.a: want 1e400, got 2e400\n",
+		},
+		{
+			name:      "type mismatch",
+			tolerance: Tolerance{Abs: 1},
+			want:      `{"a": 1}`,
+			got:       `{"a": "1"}`,
+			diff:      "$.a: want 1, got \"1\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compare := compareJSONWith(CompareOptions{Tolerance: tt.tolerance})
+
+			diff, ok := compare("want", []byte(tt.want), []byte(tt.got))
+
+			assert.Equal(t, tt.diff == "", ok)
+			assert.Equal(t, tt.diff, diff)
+		})
+	}
+}
+
+func TestAssertJSON_Tolerance(t *testing.T) {
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return false }),
+		WithTolerance(0, 1e-9),
+	)
+	ft := newFakeT("TestAssertJSON_Tolerance")
+
+	f := g.File(ft)
+	err := os.MkdirAll(filepath.Dir(f), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(
+		f, []byte(`{"name": "", "tags": null, "count": 0.3}`), 0o600,
+	)
+	require.NoError(t, err)
+
+	got := &jsonTestStruct{Count: 0.30000000000000004}
+	assert.True(t, g.AssertJSON(ft, got))
+	assert.False(t, ft.Failed(), ft.Output())
+
+	assert.False(t, g.AssertJSON(ft, &jsonTestStruct{Count: 0.4}))
+	assert.Contains(t, ft.Output(), "$.count: want 0.3, got 0.4 (delta ")
+}
+
+func TestCompareJSONWith_IgnorePaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		ignore []string
+		want   string
+		got    string
+		diff   string
+	}{
+		{
+			name:   "ignored values",
+			ignore: []string{"$.items[*].id", "$.meta.createdAt"},
+			want: `{"items": [{"id": 1, "n": "a"}, {"id": 2, "n": "b"}], ` +
+				`"meta": {"createdAt": "yesterday", "v": 1}}`,
+			got: `{"items": [{"id": 7, "n": "a"}, {"id": 8, "n": "b"}], ` +
+				`"meta": {"createdAt": "today", "v": 1}}`,
+		},
+		{
+			name:   "other values still compared",
+			ignore: []string{"$.items[*].id"},
+			want:   `{"items": [{"id": 1, "n": "a"}]}`,
+			got:    `{"items": [{"id": 2, "n": "b"}]}`,
+			diff:   "$.items[0].n: want \"a\", got \"b\"\n",
+		},
+		{
+			name:   "missing and unexpected",
+			ignore: []string{"$.a", "$.b"},
+			want:   `{"a": 1, "c": 1}`,
+			got:    `{"b": 1, "c": 1}`,
+		},
+		{
+			name:   "nested values",
+			ignore: []string{"$.meta"},
+			want:   `{"meta": {"a": [1, 2]}}`,
+			got:    `{"meta": "x"}`,
+		},
+		{
+			name:   "recursive descent",
+			ignore: []string{"$..requestId"},
+			want:   `{"requestId": 1, "a": [{"requestId": 2}]}`,
+			got:    `{"requestId": 3, "a": [{"requestId": 4}]}`,
+		},
+		{
+			name:   "invalid path",
+			ignore: []string{"requestId"},
+			want:   `{}`,
+			got:    `{}`,
+			diff: "invalid JSON path \"requestId\": " +
+				"must start with \"$\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compare := compareJSONWith(CompareOptions{IgnorePaths: tt.ignore})
+
+			diff, ok := compare("want", []byte(tt.want), []byte(tt.got))
+
+			assert.Equal(t, tt.diff == "", ok)
+			assert.Equal(t, tt.diff, diff)
+		})
+	}
+}
+
+func TestAssertJSON_IgnorePaths(t *testing.T) {
+	t.Setenv("CI", "")
+
+	for _, update := range []bool{false, true} {
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return update }),
+			IgnorePaths("$.name"),
+		)
+		ft := newFakeT("TestAssertJSON_IgnorePaths")
+
+		f := g.File(ft)
+		err := os.MkdirAll(filepath.Dir(f), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(
+			f, []byte(`{"name": "foo", "tags": null, "count": 1}`), 0o600,
+		)
+		require.NoError(t, err)
+
+		assert.True(t, g.AssertJSON(ft, &jsonTestStruct{Name: "bar", Count: 1}))
+		assert.False(t, ft.Failed(), ft.Output())
+
+		b, err := os.ReadFile(f)
+		require.NoError(t, err)
+		if update {
+			assert.Contains(t, string(b), `"name": "bar"`)
+		} else {
+			assert.Contains(t, string(b), `"name": "foo"`)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package golden
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// jsonPathAnyKey matches a single object key segment of a JSON path, as
+	// rendered by jsonPathKey.
+	jsonPathAnyKey = `(?:\.[A-Za-z_][A-Za-z0-9_]*|\["(?:[^"\\]|\\.)*"\])`
+
+	// jsonPathAnySegment matches any single segment of a JSON path.
+	jsonPathAnySegment = `(?:` + jsonPathAnyKey + `|\[\d+\])`
+)
+
+// compileJSONPath compiles a JSON path pattern into a regular expression
+// matching the paths reported by JSON comparisons. Supported are the root
+// "$", child keys ".name" and ["name"], array indexes "[0]", wildcards ".*"
+// and "[*]", and recursive descent "..name".
+func compileJSONPath(pattern string) (*regexp.Regexp, error) {
+	if !strings.HasPrefix(pattern, "$") {
+		return nil, fmt.Errorf("must start with \"$\"")
+	}
+
+	var b strings.Builder
+	b.WriteString(`^\$`)
+
+	p := pattern[1:]
+	for p != "" {
+		if strings.HasPrefix(p, "..") {
+			b.WriteString(jsonPathAnySegment + `*`)
+			p = p[1:]
+
+			// Recursive descent may be followed directly by a bracket, as in
+			// "$..[0]".
+			if strings.HasPrefix(p, ".[") {
+				p = p[1:]
+			}
+
+			continue
+		}
+
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[") + 1
+			if end == 0 {
+				end = len(p)
+			}
+
+			key := p[1:end]
+			p = p[end:]
+
+			switch key {
+			case "":
+				return nil, fmt.Errorf("empty key")
+			case "*":
+				b.WriteString(jsonPathAnyKey)
+			default:
+				b.WriteString(regexp.QuoteMeta(jsonPathKey("", key)))
+			}
+		case '[':
+			seg, rest, err := compileJSONPathBracket(p)
+			if err != nil {
+				return nil, err
+			}
+
+			b.WriteString(seg)
+			p = rest
+		default:
+			return nil, fmt.Errorf("unexpected %q", p[0])
+		}
+	}
+
+	b.WriteString(`$`)
+
+	return regexp.Compile(b.String())
+}
+
+// compileJSONPathBracket compiles the bracketed segment at the start of p into
+// a regular expression, returning it along with the remainder of p.
+func compileJSONPathBracket(p string) (string, string, error) {
+	if len(p) > 1 && (p[1] == '"' || p[1] == '\'') {
+		quote := p[1]
+		end := 2
+		for end < len(p) && p[end] != quote {
+			if p[end] == '\\' {
+				end++
+			}
+			end++
+		}
+
+		if end+1 >= len(p) || p[end+1] != ']' {
+			return "", "", fmt.Errorf("unterminated key")
+		}
+
+		key := p[2:end]
+		if quote == '"' {
+			var err error
+			key, err = strconv.Unquote(p[1 : end+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid key %s", p[1:end+1])
+			}
+		}
+
+		return regexp.QuoteMeta(jsonPathKey("", key)), p[end+2:], nil
+	}
+
+	end := strings.IndexByte(p, ']')
+	if end == -1 {
+		return "", "", fmt.Errorf("unterminated \"[\"")
+	}
+
+	index := p[1:end]
+	if index == "*" {
+		return jsonPathAnySegment, p[end+1:], nil
+	}
+
+	if _, err := strconv.ParseUint(index, 10, 64); err != nil {
+		return "", "", fmt.Errorf("invalid index %q", index)
+	}
+
+	return regexp.QuoteMeta("[" + index + "]"), p[end+1:], nil
+}
+
+// compileJSONPaths compiles all given JSON path patterns.
+func compileJSONPaths(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := compileJSONPath(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON path %q: %w", pattern, err)
+		}
+
+		res = append(res, re)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,99 @@
+package golden
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileJSONPath(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		match     []string
+		noMatch   []string
+		wantError string
+	}{
+		{
+			pattern: "$",
+			match:   []string{"$"},
+			noMatch: []string{"$.a", "$[0]"},
+		},
+		{
+			pattern: "$.meta.createdAt",
+			match:   []string{"$.meta.createdAt"},
+			noMatch: []string{
+				"$.meta", "$.meta.createdAtX", "$.meta.createdAt.x",
+				"$.metaXcreatedAt",
+			},
+		},
+		{
+			pattern: "$.items[*].id",
+			match:   []string{"$.items[0].id", "$.items[12].id"},
+			noMatch: []string{"$.items.id", "$.items[0].idx", "$.items[0]"},
+		},
+		{
+			pattern: "$.items[1]",
+			match:   []string{"$.items[1]"},
+			noMatch: []string{"$.items[0]", "$.items[10]"},
+		},
+		{
+			pattern: "$.*.id",
+			match:   []string{"$.a.id", `$["foo bar"].id`},
+			noMatch: []string{"$[0].id", "$.a.b.id"},
+		},
+		{
+			pattern: `$["foo bar"]['a.b']`,
+			match:   []string{`$["foo bar"]["a.b"]`},
+			noMatch: []string{`$["foo bar"].a`},
+		},
+		{
+			pattern: `$["name"]`,
+			match:   []string{"$.name"},
+		},
+		{
+			pattern: "$..id",
+			match:   []string{"$.id", "$.a.id", `$.a[0]["x y"].id`},
+			noMatch: []string{"$.a.idx", "$.a.id.b"},
+		},
+		{
+			pattern: "$..[0]",
+			match:   []string{"$[0]", "$.a.b[0]"},
+			noMatch: []string{"$.a[1]"},
+		},
+		{pattern: "meta", wantError: `must start with "$"`},
+		{pattern: "$.", wantError: "empty key"},
+		{pattern: "$.a[", wantError: `unterminated "["`},
+		{pattern: "$[x]", wantError: `invalid index "x"`},
+		{pattern: `$["a]`, wantError: "unterminated key"},
+		{pattern: "$a", wantError: `unexpected 'a'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := compileJSONPath(tt.pattern)
+
+			if tt.wantError != "" {
+				assert.EqualError(t, err, tt.wantError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			for _, p := range tt.match {
+				assert.True(t, re.MatchString(p), "should match %s", p)
+			}
+			for _, p := range tt.noMatch {
+				assert.False(t, re.MatchString(p), "should not match %s", p)
+			}
+		})
+	}
+}
+
+func TestCompileJSONPaths(t *testing.T) {
+	res, err := compileJSONPaths([]string{"$.a", "$.b"})
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	_, err = compileJSONPaths([]string{"$.a", "b"})
+	assert.EqualError(t, err, `invalid JSON path "b": must start with "$"`)
+}
@@ -0,0 +1,103 @@
+package golden
+
+import (
+	"bytes"
+)
+
+// Normalization is a set of flags determining how line endings and whitespace
+// of golden file content and actual data are normalized before being written,
+// read, or compared. Flags can be combined with the bitwise OR operator.
+type Normalization int
+
+const (
+	// NormalizeLineEndings replaces all CRLF ("\r\n") line endings with LF
+	// ("\n"), so golden files checked out with CRLF line endings on Windows
+	// still match.
+	NormalizeLineEndings Normalization = 1 << iota
+
+	// NormalizeTrailingSpace removes spaces and tabs from the end of every
+	// line.
+	NormalizeTrailingSpace
+
+	// NormalizeFinalNewline ensures non-empty data ends with exactly one LF
+	// ("\n"), removing any additional trailing line endings.
+	NormalizeFinalNewline
+
+	// NormalizeNone disables all normalization.
+	NormalizeNone Normalization = 0
+
+	// NormalizeAll enables all normalization.
+	NormalizeAll = NormalizeLineEndings | NormalizeTrailingSpace |
+		NormalizeFinalNewline
+)
+
+// Has returns true if all flags in flag are set in n.
+func (n Normalization) Has(flag Normalization) bool {
+	return n&flag == flag
+}
+
+// Apply returns a copy of data normalized according to n. Line endings are
+// normalized first, followed by trailing whitespace, and finally the trailing
+// newline.
+func (n Normalization) Apply(data []byte) []byte {
+	if n == NormalizeNone || len(data) == 0 {
+		return data
+	}
+
+	if n.Has(NormalizeLineEndings) {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+
+	if n.Has(NormalizeTrailingSpace) {
+		data = trimTrailingSpace(data)
+	}
+
+	if n.Has(NormalizeFinalNewline) {
+		data = bytes.TrimRight(data, "\r\n")
+		if len(data) > 0 {
+			data = append(data[:len(data):len(data)], '\n')
+		}
+	}
+
+	return data
+}
+
+// trimTrailingSpace removes spaces and tabs from the end of every line in
+// data, keeping any CRLF line endings intact.
+func trimTrailingSpace(data []byte) []byte {
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		cr := bytes.HasSuffix(line, []byte("\r"))
+		if cr {
+			line = line[:len(line)-1]
+		}
+
+		line = bytes.TrimRight(line, " \t")
+		if cr {
+			line = append(line[:len(line):len(line)], '\r')
+		}
+
+		lines[i] = line
+	}
+
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// Normalize returns a copy of data normalized according to the Normalization
+// of the Default *Golden instance.
+//
+// This is a wrapper around calling Normalize() on the Default *Golden
+// instance.
+func Normalize(data []byte) []byte {
+	return Default.Normalize(data)
+}
+
+// Normalize returns a copy of data normalized according to Normalization. If
+// Normalization is NormalizeNone, data is returned as is.
+//
+// Normalization is applied automatically when reading, writing and comparing
+// golden files. Normalize is useful to compare data against the content
+// returned by Do(), Get() and friends.
+func (s *Golden) Normalize(data []byte) []byte {
+	return s.Normalization.Apply(data)
+}
@@ -0,0 +1,208 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalization_Apply(t *testing.T) {
+	tests := []struct {
+		name string
+		n    Normalization
+		data string
+		want string
+	}{
+		{
+			name: "none",
+			n:    NormalizeNone,
+			data: "foo \r\nbar\t\r\n\r\n",
+			want: "foo \r\nbar\t\r\n\r\n",
+		},
+		{
+			name: "empty",
+			n:    NormalizeAll,
+			data: "",
+			want: "",
+		},
+		{
+			name: "line endings",
+			n:    NormalizeLineEndings,
+			data: "foo \r\nbar\rbaz\t\r\n\r\n",
+			want: "foo \nbar\rbaz\t\n\n",
+		},
+		{
+			name: "trailing space",
+			n:    NormalizeTrailingSpace,
+			data: "foo  \nbar\t \r\n  baz\n \t",
+			want: "foo\nbar\r\n  baz\n",
+		},
+		{
+			name: "final newline missing",
+			n:    NormalizeFinalNewline,
+			data: "foo\nbar",
+			want: "foo\nbar\n",
+		},
+		{
+			name: "final newline repeated",
+			n:    NormalizeFinalNewline,
+			data: "foo\nbar\r\n\n\r\n",
+			want: "foo\nbar\n",
+		},
+		{
+			name: "final newline only newlines",
+			n:    NormalizeFinalNewline,
+			data: "\n\n",
+			want: "",
+		},
+		{
+			name: "all",
+			n:    NormalizeAll,
+			data: "foo  \r\nbar\t\r\n\r\n",
+			want: "foo\nbar\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.data)
+
+			got := tt.n.Apply(data)
+
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.data, string(data), "input must not be modified")
+		})
+	}
+}
+
+func TestNormalization_Has(t *testing.T) {
+	assert.True(t, NormalizeAll.Has(NormalizeLineEndings))
+	assert.True(t, NormalizeAll.Has(NormalizeTrailingSpace|
+		NormalizeFinalNewline))
+	assert.False(t, NormalizeLineEndings.Has(NormalizeTrailingSpace))
+	assert.False(t, NormalizeLineEndings.Has(NormalizeAll))
+	assert.True(t, NormalizeNone.Has(NormalizeNone))
+}
+
+func TestGolden_Normalization(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		n          Normalization
+		update     bool
+		golden     string
+		got        string
+		want       bool
+		wantFile   string
+		wantGet    string
+		wantErrors []string
+	}{
+		{
+			name:     "crlf golden file",
+			n:        NormalizeLineEndings,
+			golden:   "foo\r\nbar\r\n",
+			got:      "foo\nbar\n",
+			want:     true,
+			wantFile: "foo\r\nbar\r\n",
+			wantGet:  "foo\nbar\n",
+		},
+		{
+			name:     "crlf actual",
+			n:        NormalizeAll,
+			golden:   "foo\nbar\n",
+			got:      "foo \r\nbar",
+			want:     true,
+			wantFile: "foo\nbar\n",
+			wantGet:  "foo\nbar\n",
+		},
+		{
+			name:     "update",
+			n:        NormalizeAll,
+			update:   true,
+			golden:   "old",
+			got:      "foo \r\nbar\r\n\r\n",
+			want:     true,
+			wantFile: "foo\nbar\n",
+			wantGet:  "foo\nbar\n",
+		},
+		{
+			name:     "disabled",
+			n:        NormalizeNone,
+			golden:   "foo\r\nbar\r\n",
+			got:      "foo\nbar\n",
+			want:     false,
+			wantFile: "foo\r\nbar\r\n",
+			wantGet:  "foo\r\nbar\r\n",
+			wantErrors: []string{
+				"-foo\r\n-bar\r\n+foo\n+bar\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+				WithNormalization(tt.n),
+			)
+			ft := newFakeT("TestGolden_Normalization/" + tt.name)
+
+			f := g.File(ft)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte(tt.golden), 0o600)
+			require.NoError(t, err)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.Assert(ft, []byte(tt.got)) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			b, err := os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, string(b))
+			assert.Equal(t, tt.wantGet, string(g.Get(ft)))
+		})
+	}
+}
+
+func TestGolden_Normalization_With(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return false }),
+	)
+	ft := newFakeT("TestGolden_Normalization_With")
+
+	f := g.File(ft)
+	err := os.MkdirAll(filepath.Dir(f), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(f, []byte("foo\r\n"), 0o600)
+	require.NoError(t, err)
+
+	ng := g.With(WithNormalization(NormalizeLineEndings))
+
+	assert.True(t, ng.Assert(ft, []byte("foo\n")))
+	assert.False(t, ft.Failed(), ft.Output())
+	assert.Equal(t, NormalizeNone, g.Normalization)
+
+	assert.False(t, g.Assert(ft, []byte("foo\n")))
+	assert.True(t, ft.Failed())
+}
+
+func TestNormalize(t *testing.T) {
+	orig := Default
+	t.Cleanup(func() { Default = orig })
+
+	Default = New(WithNormalization(NormalizeAll))
+
+	assert.Equal(t, []byte("foo\n"), Normalize([]byte("foo \r\n\r\n")))
+}
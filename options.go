@@ -41,3 +41,149 @@ func WithUpdateFunc(updateFunc UpdateFunc) Option {
 		g.UpdateFunc = updateFunc
 	}
 }
+
+// WithUpdateTestFunc sets the per-test update function for a Golden instance.
+func WithUpdateTestFunc(updateTestFunc UpdateTestFunc) Option {
+	return func(g *Golden) {
+		g.UpdateTestFunc = updateTestFunc
+	}
+}
+
+// WithUpdateModeFunc sets the update mode function for a Golden instance.
+func WithUpdateModeFunc(updateModeFunc UpdateModeFunc) Option {
+	return func(g *Golden) {
+		g.UpdateModeFunc = updateModeFunc
+	}
+}
+
+// WithUpdateMode sets a fixed update mode for a Golden instance.
+func WithUpdateMode(mode UpdateMode) Option {
+	return func(g *Golden) {
+		g.UpdateModeFunc = func() UpdateMode { return mode }
+	}
+}
+
+// WithCIFunc sets the CI detection function for a Golden instance.
+func WithCIFunc(ciFunc CIFunc) Option {
+	return func(g *Golden) {
+		g.CIFunc = ciFunc
+	}
+}
+
+// WithAllowCIUpdate sets if golden files may be written in CI for a Golden
+// instance.
+func WithAllowCIUpdate(allow bool) Option {
+	return func(g *Golden) {
+		g.AllowCIUpdate = allow
+	}
+}
+
+// WithFailMode sets the fail mode for a Golden instance.
+func WithFailMode(mode FailMode) Option {
+	return func(g *Golden) {
+		g.FailMode = mode
+	}
+}
+
+// WithDiffer sets the differ used to describe mismatches for a Golden instance.
+func WithDiffer(differ Differ) Option {
+	return func(g *Golden) {
+		g.Differ = differ
+	}
+}
+
+// WithActualFiles sets if ".actual" files are written on mismatch for a Golden
+// instance.
+func WithActualFiles(enabled bool) Option {
+	return func(g *Golden) {
+		g.ActualFiles = enabled
+	}
+}
+
+// WithCodec registers a codec for the given file extension, including the
+// leading dot, like ".json", for a Golden instance. A nil codec removes any
+// codec registered for the extension.
+func WithCodec(ext string, codec Codec) Option {
+	return func(g *Golden) {
+		codecs := cloneCodecs(g.Codecs)
+		if codecs == nil {
+			codecs = map[string]Codec{}
+		}
+
+		if codec == nil {
+			delete(codecs, ext)
+		} else {
+			codecs[ext] = codec
+		}
+
+		g.Codecs = codecs
+	}
+}
+
+// WithScrubbers appends scrubbers to the scrubbers of a Golden instance.
+func WithScrubbers(scrubbers ...Scrubber) Option {
+	return func(g *Golden) {
+		s := make([]Scrubber, 0, len(g.Scrubbers)+len(scrubbers))
+		s = append(s, g.Scrubbers...)
+		g.Scrubbers = append(s, scrubbers...)
+	}
+}
+
+// WithNormalization sets the line ending and whitespace normalization for a
+// Golden instance.
+func WithNormalization(n Normalization) Option {
+	return func(g *Golden) {
+		g.Normalization = n
+	}
+}
+
+// WithTolerance sets the absolute and relative tolerance used when comparing
+// numbers in structured golden files for a Golden instance.
+func WithTolerance(abs, rel float64) Option {
+	return func(g *Golden) {
+		g.Tolerance = Tolerance{Abs: abs, Rel: rel}
+	}
+}
+
+// IgnorePaths appends JSON paths of values which are not compared by
+// AssertJSON() and friends for a Golden instance. See
+// CompareOptions.IgnorePaths for the supported syntax.
+func IgnorePaths(paths ...string) Option {
+	return func(g *Golden) {
+		p := make([]string, 0, len(g.IgnorePaths)+len(paths))
+		p = append(p, g.IgnorePaths...)
+		g.IgnorePaths = append(p, paths...)
+	}
+}
+
+// WithStorage sets how golden files are stored on disk for a Golden instance.
+func WithStorage(storage Storage) Option {
+	return func(g *Golden) {
+		g.Storage = storage
+	}
+}
+
+// WithIgnoreHeaders sets the names of HTTP headers which are left out of
+// serialized responses for a Golden instance, replacing any existing ones,
+// including the DefaultIgnoreHeaders.
+func WithIgnoreHeaders(headers ...string) Option {
+	return func(g *Golden) {
+		g.IgnoreHeaders = append([]string(nil), headers...)
+	}
+}
+
+// WithRemoveOrphans sets if CheckOrphans() removes orphaned golden files for a
+// Golden instance.
+func WithRemoveOrphans(remove bool) Option {
+	return func(g *Golden) {
+		g.RemoveOrphans = remove
+	}
+}
+
+// WithVerifyGets sets if golden files which are read must also be compared
+// before the test finishes for a Golden instance.
+func WithVerifyGets(enabled bool) Option {
+	return func(g *Golden) {
+		g.VerifyGets = enabled
+	}
+}
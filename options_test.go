@@ -56,3 +56,190 @@ func TestWithUpdateFunc(t *testing.T) {
 
 	assertSameFunc(t, customUpdateFunc, g.UpdateFunc)
 }
+
+func TestWithUpdateTestFunc(t *testing.T) {
+	customUpdateTestFunc := func(TestingT, string) bool { return true }
+	g := &Golden{}
+
+	opt := WithUpdateTestFunc(customUpdateTestFunc)
+	opt(g)
+
+	assertSameFunc(t, customUpdateTestFunc, g.UpdateTestFunc)
+}
+
+func TestWithUpdateModeFunc(t *testing.T) {
+	customUpdateModeFunc := func() UpdateMode { return UpdateMissing }
+	g := &Golden{}
+
+	opt := WithUpdateModeFunc(customUpdateModeFunc)
+	opt(g)
+
+	assertSameFunc(t, customUpdateModeFunc, g.UpdateModeFunc)
+}
+
+func TestWithUpdateMode(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithUpdateMode(UpdateMissing)
+	opt(g)
+
+	assert.Equal(t, UpdateMissing, g.UpdateModeFunc())
+}
+
+func TestWithCIFunc(t *testing.T) {
+	customCIFunc := func() bool { return true }
+	g := &Golden{}
+
+	opt := WithCIFunc(customCIFunc)
+	opt(g)
+
+	assertSameFunc(t, customCIFunc, g.CIFunc)
+}
+
+func TestWithAllowCIUpdate(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithAllowCIUpdate(true)
+	opt(g)
+
+	assert.True(t, g.AllowCIUpdate)
+}
+
+func TestWithFailMode(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithFailMode(FailError)
+	opt(g)
+
+	assert.Equal(t, FailError, g.FailMode)
+}
+
+func TestWithDiffer(t *testing.T) {
+	customDiffer := &UnifiedDiffer{Context: 10}
+	g := &Golden{}
+
+	opt := WithDiffer(customDiffer)
+	opt(g)
+
+	assert.Same(t, customDiffer, g.Differ)
+}
+
+func TestWithActualFiles(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithActualFiles(true)
+	opt(g)
+
+	assert.True(t, g.ActualFiles)
+}
+
+func TestWithCodec(t *testing.T) {
+	jsonCodec := &JSONCodec{}
+	xmlCodec := &XMLCodec{}
+	codecs := map[string]Codec{".json": jsonCodec}
+	g := &Golden{Codecs: codecs}
+
+	opt := WithCodec(".xml", xmlCodec)
+	opt(g)
+
+	assert.Equal(t, map[string]Codec{".json": jsonCodec, ".xml": xmlCodec},
+		g.Codecs,
+	)
+	assert.Equal(t, map[string]Codec{".json": jsonCodec}, codecs)
+
+	opt = WithCodec(".json", nil)
+	opt(g)
+
+	assert.Equal(t, map[string]Codec{".xml": xmlCodec}, g.Codecs)
+
+	g = &Golden{}
+	opt = WithCodec(".json", jsonCodec)
+	opt(g)
+
+	assert.Equal(t, map[string]Codec{".json": jsonCodec}, g.Codecs)
+}
+
+func TestWithScrubbers(t *testing.T) {
+	uuid := UUIDScrubber()
+	timestamp := TimestampScrubber()
+	scrubbers := []Scrubber{uuid}
+	g := &Golden{Scrubbers: scrubbers}
+
+	opt := WithScrubbers(timestamp)
+	opt(g)
+
+	assert.Equal(t, []Scrubber{uuid, timestamp}, g.Scrubbers)
+	assert.Equal(t, []Scrubber{uuid}, scrubbers)
+}
+
+func TestWithNormalization(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithNormalization(NormalizeAll)
+	opt(g)
+
+	assert.Equal(t, NormalizeAll, g.Normalization)
+}
+
+func TestWithTolerance(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithTolerance(1e-12, 1e-9)
+	opt(g)
+
+	assert.Equal(t, Tolerance{Abs: 1e-12, Rel: 1e-9}, g.Tolerance)
+}
+
+func TestIgnorePaths(t *testing.T) {
+	paths := []string{"$.a"}
+	g := &Golden{IgnorePaths: paths}
+
+	opt := IgnorePaths("$.b", "$.c")
+	opt(g)
+
+	assert.Equal(t, []string{"$.a", "$.b", "$.c"}, g.IgnorePaths)
+	assert.Equal(t, []string{"$.a"}, paths)
+}
+
+func TestWithStorage(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithStorage(StorageTxtar)
+	opt(g)
+
+	assert.Equal(t, StorageTxtar, g.Storage)
+}
+
+func TestWithIgnoreHeaders(t *testing.T) {
+	headers := []string{"Date"}
+	g := &Golden{IgnoreHeaders: headers}
+
+	opt := WithIgnoreHeaders("Server", "X-Request-Id")
+	opt(g)
+
+	assert.Equal(t, []string{"Server", "X-Request-Id"}, g.IgnoreHeaders)
+	assert.Equal(t, []string{"Date"}, headers)
+
+	opt = WithIgnoreHeaders()
+	opt(g)
+
+	assert.Empty(t, g.IgnoreHeaders)
+}
+
+func TestWithRemoveOrphans(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithRemoveOrphans(true)
+	opt(g)
+
+	assert.True(t, g.RemoveOrphans)
+}
+
+func TestWithVerifyGets(t *testing.T) {
+	g := &Golden{}
+
+	opt := WithVerifyGets(true)
+	opt(g)
+
+	assert.True(t, g.VerifyGets)
+}
@@ -0,0 +1,217 @@
+package golden
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TestingM is the subset of *testing.M used by CheckOrphans().
+type TestingM interface {
+	Run() int
+}
+
+// touched records the absolute path of every golden file resolved by any
+// *Golden instance during the current test run.
+var touched = struct {
+	sync.Mutex
+	paths map[string]struct{}
+}{paths: map[string]struct{}{}}
+
+func touch(path string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	touched.Lock()
+	defer touched.Unlock()
+
+	touched.paths[abs] = struct{}{}
+}
+
+func isTouched(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	touched.Lock()
+	defer touched.Unlock()
+
+	_, ok := touched.paths[abs]
+
+	return ok
+}
+
+// skipper is implemented by *testing.T, allowing CheckOrphans() to tell if any
+// test was skipped.
+type skipper interface {
+	Skipped() bool
+}
+
+// observed holds every TestingT instance which implements skipper, and has
+// resolved the path of a golden file during the current test run.
+var observed = struct {
+	sync.Mutex
+	m map[TestingT]skipper
+}{m: map[TestingT]skipper{}}
+
+func observe(t TestingT) {
+	sk, ok := t.(skipper)
+	if !ok || !isComparable(t) {
+		return
+	}
+
+	observed.Lock()
+	defer observed.Unlock()
+
+	observed.m[t] = sk
+}
+
+// anySkipped returns true if any observed TestingT instance was skipped.
+func anySkipped() bool {
+	observed.Lock()
+	defer observed.Unlock()
+
+	for _, sk := range observed.m {
+		if sk.Skipped() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckOrphans runs all tests with m.Run(), and then reports any golden files
+// within the Dirname directory of the Default instance which were not used by
+// any test. It is intended to be called from TestMain():
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(golden.CheckOrphans(m))
+//	}
+//
+// See (*Golden).CheckOrphans() for details.
+func CheckOrphans(m TestingM) int {
+	return Default.CheckOrphans(m)
+}
+
+// CheckOrphans runs all tests with m.Run(), and then reports any golden files
+// within the Dirname directory which were not used by any test. Orphaned golden
+// files are typically left behind by tests which have been removed or renamed.
+//
+// Golden files are considered used when their filename has been resolved by
+// any *Golden instance, via any of its methods. Orphaned golden files are
+// reported on stderr, and cause a non-zero exit code to be returned. They are
+// never removed, unless RemoveOrphans is true. Like writing golden files,
+// removal is not allowed in CI unless AllowCIUpdate is true.
+//
+// The check is skipped when tests fail, when only a subset of tests is run due
+// to the -test.run, -test.skip, or -test.list flags being set, when the
+// -test.short flag is set, or when any test which used golden files was
+// skipped. In all cases the exit code from m.Run() is returned unless orphans
+// are found.
+//
+// Golden files can only be recognized as used by tests which actually ran.
+// Tests which are excluded by build constraints, like GOOS or build tags, or
+// which are skipped before using any golden files, cannot be detected, and
+// their golden files are reported as orphans. Review the reported files before
+// enabling RemoveOrphans.
+func (s *Golden) CheckOrphans(m TestingM) int {
+	code := m.Run()
+	if code != 0 || isRunFiltered() || anySkipped() {
+		return code
+	}
+
+	return s.checkOrphans(os.Stderr)
+}
+
+func (s *Golden) checkOrphans(w io.Writer) int {
+	orphans, err := s.orphans()
+	if err != nil {
+		fmt.Fprintf(w, "golden: failed to check for orphaned files: %s\n", err)
+
+		return 1
+	}
+
+	if len(orphans) == 0 {
+		return 0
+	}
+
+	if s.RemoveOrphans && (!s.CI() || s.AllowCIUpdate) {
+		code := 0
+		for _, f := range orphans {
+			fmt.Fprintf(w, "golden: removing orphaned file: %s\n", f)
+
+			if err := os.Remove(f); err != nil {
+				fmt.Fprintf(w, "golden: failed to remove file: %s\n", err)
+				code = 1
+			}
+		}
+
+		return code
+	}
+
+	for _, f := range orphans {
+		fmt.Fprintf(w, "golden: orphaned file: %s\n", f)
+	}
+
+	return 1
+}
+
+// orphans returns the sorted list of golden files within Dirname which have
+// not been touched.
+func (s *Golden) orphans() ([]string, error) {
+	var orphans []string
+
+	err := filepath.Walk(s.Dirname,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !info.IsDir() && s.isGoldenFile(path) && !isTouched(path) {
+				orphans = append(orphans, path)
+			}
+
+			return nil
+		},
+	)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(orphans)
+
+	return orphans, nil
+}
+
+// isGoldenFile returns true if path has the filename suffix of golden files,
+// or of txtar archives when Storage is StorageTxtar.
+func (s *Golden) isGoldenFile(path string) bool {
+	return strings.HasSuffix(path, s.Suffix) ||
+		(s.Storage == StorageTxtar && strings.HasSuffix(path, txtarSuffix))
+}
+
+// isRunFiltered returns true if the go test flags in use only run a subset of
+// all tests, or make tests skip themselves via testing.Short().
+var isRunFiltered = func() bool {
+	for _, name := range []string{"test.run", "test.skip", "test.list"} {
+		if f := flag.Lookup(name); f != nil && f.Value.String() != "" {
+			return true
+		}
+	}
+
+	if f := flag.Lookup("test.short"); f != nil && f.Value.String() == "true" {
+		return true
+	}
+
+	return false
+}
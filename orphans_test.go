@@ -0,0 +1,232 @@
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeM struct {
+	run  func()
+	code int
+}
+
+func (m *fakeM) Run() int {
+	if m.run != nil {
+		m.run()
+	}
+
+	return m.code
+}
+
+func writeOrphanFiles(t *testing.T, dir string, files ...string) {
+	t.Helper()
+
+	for _, f := range files {
+		f = filepath.Join(dir, filepath.FromSlash(f))
+		require.NoError(t, os.MkdirAll(filepath.Dir(f), 0o755))
+		require.NoError(t, os.WriteFile(f, []byte("content"), 0o644))
+	}
+}
+
+func TestGolden_CheckOrphans(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name     string
+		files    []string
+		update   bool
+		mode     UpdateMode
+		remove   bool
+		filtered bool
+		skipped  bool
+		code     int
+		want     int
+		output   []string
+		exist    []string
+		removed  []string
+	}{
+		{
+			name:  "no orphans",
+			files: []string{"TestFoo.golden", "TestFoo/bar.golden"},
+			want:  0,
+			exist: []string{"TestFoo.golden", "TestFoo/bar.golden"},
+		},
+		{
+			name: "orphans",
+			want: 1,
+			output: []string{
+				"golden: orphaned file: %s/TestBar.golden\n",
+				"golden: orphaned file: %s/TestBar/baz.golden\n",
+			},
+			exist: []string{
+				"TestFoo.golden", "TestBar.golden", "TestBar/baz.golden",
+				"TestBar/other.txt",
+			},
+		},
+		{
+			name:   "orphans with update",
+			update: true,
+			want:   1,
+			output: []string{
+				"golden: orphaned file: %s/TestBar.golden\n",
+				"golden: orphaned file: %s/TestBar/baz.golden\n",
+			},
+			exist: []string{
+				"TestFoo.golden", "TestBar.golden", "TestBar/baz.golden",
+				"TestBar/other.txt",
+			},
+		},
+		{
+			name:   "orphans with remove",
+			remove: true,
+			want:   0,
+			output: []string{
+				"golden: removing orphaned file: %s/TestBar.golden\n",
+				"golden: removing orphaned file: %s/TestBar/baz.golden\n",
+			},
+			exist:   []string{"TestFoo.golden", "TestBar/other.txt"},
+			removed: []string{"TestBar.golden", "TestBar/baz.golden"},
+		},
+		{
+			name:    "skipped test",
+			remove:  true,
+			skipped: true,
+			want:    0,
+			exist: []string{
+				"TestFoo.golden", "TestBar.golden", "TestBar/baz.golden",
+			},
+		},
+		{
+			name: "failed tests",
+			code: 3,
+			want: 3,
+			exist: []string{
+				"TestFoo.golden", "TestBar.golden", "TestBar/baz.golden",
+			},
+		},
+		{
+			name:     "filtered test run",
+			filtered: true,
+			want:     0,
+			exist: []string{
+				"TestFoo.golden", "TestBar.golden", "TestBar/baz.golden",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := tt.files
+			if files == nil {
+				files = []string{
+					"TestFoo.golden", "TestFoo/bar.golden", "TestBar.golden",
+					"TestBar/baz.golden", "TestBar/other.txt",
+				}
+			}
+			dir := t.TempDir()
+			writeOrphanFiles(t, dir, files...)
+
+			origFiltered := isRunFiltered
+			t.Cleanup(func() { isRunFiltered = origFiltered })
+			isRunFiltered = func() bool { return tt.filtered }
+
+			g := New(
+				WithDirname(dir),
+				WithUpdateFunc(func() bool { return tt.update }),
+				WithUpdateMode(tt.mode),
+				WithRemoveOrphans(tt.remove),
+			)
+			m := &fakeM{
+				code: tt.code,
+				run: func() {
+					g.File(newFakeT("TestFoo"))
+					g.FileP(newFakeT("TestFoo"), "bar")
+					if tt.skipped {
+						st := skippedT{newFakeT("TestSkipped")}
+						t.Cleanup(func() { unobserve(st) })
+						g.File(st)
+					}
+				},
+			}
+
+			r, w, err := os.Pipe()
+			require.NoError(t, err)
+			origStderr := os.Stderr
+			os.Stderr = w
+			got := g.CheckOrphans(m)
+			os.Stderr = origStderr
+			require.NoError(t, w.Close())
+
+			var buf bytes.Buffer
+			_, err = buf.ReadFrom(r)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got)
+
+			var want string
+			for _, line := range tt.output {
+				want += filepath.FromSlash(
+					fmt.Sprintf(line, filepath.ToSlash(dir)),
+				)
+			}
+			assert.Equal(t, want, buf.String())
+
+			for _, f := range tt.exist {
+				assert.FileExists(t, filepath.Join(dir, f))
+			}
+			for _, f := range tt.removed {
+				assert.NoFileExists(t, filepath.Join(dir, f))
+			}
+		})
+	}
+}
+
+// skippedT is a TestingT implementation which reports itself as skipped.
+type skippedT struct {
+	*fakeT
+}
+
+func (skippedT) Skipped() bool {
+	return true
+}
+
+func unobserve(t TestingT) {
+	observed.Lock()
+	defer observed.Unlock()
+
+	delete(observed.m, t)
+}
+
+func TestGolden_CheckOrphans_CI(t *testing.T) {
+	t.Setenv("CI", "true")
+
+	dir := t.TempDir()
+	writeOrphanFiles(t, dir, "TestBar.golden")
+
+	g := New(WithDirname(dir), WithRemoveOrphans(true))
+
+	var buf bytes.Buffer
+	got := g.checkOrphans(&buf)
+
+	assert.Equal(t, 1, got)
+	assert.Equal(t,
+		"golden: orphaned file: "+filepath.Join(dir, "TestBar.golden")+"\n",
+		buf.String(),
+	)
+	assert.FileExists(t, filepath.Join(dir, "TestBar.golden"))
+}
+
+func TestGolden_CheckOrphans_MissingDirname(t *testing.T) {
+	g := New(WithDirname(filepath.Join(t.TempDir(), "nope")))
+
+	var buf bytes.Buffer
+	got := g.checkOrphans(&buf)
+
+	assert.Equal(t, 0, got)
+	assert.Empty(t, buf.String())
+}
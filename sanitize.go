@@ -1,6 +1,7 @@
 package golden
 
 import (
+	"os"
 	"regexp"
 	"strings"
 )
@@ -32,3 +33,15 @@ func sanitizeFilename(name string) string {
 
 	return r
 }
+
+// sanitizePath sanitizes each element of the given path with
+// sanitizeFilename().
+func sanitizePath(path string) string {
+	dirty := strings.Split(path, string(os.PathSeparator))
+	clean := make([]string, 0, len(dirty))
+	for _, s := range dirty {
+		clean = append(clean, sanitizeFilename(s))
+	}
+
+	return strings.Join(clean, string(os.PathSeparator))
+}
@@ -0,0 +1,137 @@
+package golden
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Scrubber replaces volatile content, like timestamps, UUIDs and temporary
+// paths, with stable placeholders. Scrubbers are registered on a *Golden
+// instance via its Scrubbers field, and are applied to data before it is
+// written to a golden file, and before it is compared against one.
+type Scrubber interface {
+	// Scrub returns a copy of data with all volatile content replaced.
+	Scrub(data []byte) []byte
+}
+
+// ScrubberFunc is an adapter to allow the use of ordinary functions as a
+// Scrubber.
+type ScrubberFunc func(data []byte) []byte
+
+var _ Scrubber = ScrubberFunc(nil)
+
+// Scrub calls f(data).
+func (f ScrubberFunc) Scrub(data []byte) []byte {
+	return f(data)
+}
+
+var (
+	timestampRegexp = regexp.MustCompile(
+		`\b\d{4}-\d{2}-\d{2}[Tt]\d{2}:\d{2}:\d{2}(?:\.\d+)?` +
+			`(?:[Zz]|[+-]\d{2}:\d{2})`,
+	)
+	uuidRegexp = regexp.MustCompile(
+		`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-` +
+			`[0-9a-fA-F]{12}\b`,
+	)
+)
+
+// RegexpScrubber returns a Scrubber which replaces all matches of re with a
+// numbered placeholder based on name, like "<NAME-1>". Identical matches get
+// the same placeholder, and numbers are assigned in order of first
+// appearance, so that golden files still show which values are the same.
+func RegexpScrubber(re *regexp.Regexp, name string) Scrubber {
+	return &regexpScrubber{re: re, name: name}
+}
+
+// TimestampScrubber returns a Scrubber which replaces RFC 3339 timestamps,
+// like "2006-01-02T15:04:05Z" or "2006-01-02T15:04:05.999+07:00", with
+// numbered "<TIMESTAMP-1>" placeholders.
+func TimestampScrubber() Scrubber {
+	return RegexpScrubber(timestampRegexp, "TIMESTAMP")
+}
+
+// UUIDScrubber returns a Scrubber which replaces UUIDs in their canonical
+// hyphenated form with numbered "<UUID-1>" placeholders.
+func UUIDScrubber() Scrubber {
+	return RegexpScrubber(uuidRegexp, "UUID")
+}
+
+// HexIDScrubber returns a Scrubber which replaces hexadecimal strings of at
+// least minLen characters, like commit hashes and trace IDs, with numbered
+// "<HEXID-1>" placeholders. As decimal numbers are valid hexadecimal strings
+// too, minLen should be large enough to not match other numbers.
+//
+// When combined with UUIDScrubber(), it should be placed after it, as parts of
+// UUIDs may otherwise be replaced.
+func HexIDScrubber(minLen int) Scrubber {
+	if minLen < 1 {
+		minLen = 1
+	}
+
+	return RegexpScrubber(
+		regexp.MustCompile(`\b[0-9a-fA-F]{`+strconv.Itoa(minLen)+`,}\b`),
+		"HEXID",
+	)
+}
+
+// TempDirScrubber returns a Scrubber which replaces directories created by
+// t.TempDir() with numbered "<TEMPDIR-1>" placeholders. Anything following
+// the directory, like the names of files within it, is kept as is.
+//
+// Temporary directories are matched by the location reported by os.TempDir()
+// when TempDirScrubber() is called.
+func TempDirScrubber() Scrubber {
+	sep := regexp.QuoteMeta(string(os.PathSeparator))
+	re := regexp.MustCompile(
+		regexp.QuoteMeta(os.TempDir()) + sep + `[^` + sep + `\s]+` + sep +
+			`\d{3,}`,
+	)
+
+	return RegexpScrubber(re, "TEMPDIR")
+}
+
+type regexpScrubber struct {
+	re   *regexp.Regexp
+	name string
+}
+
+var _ Scrubber = (*regexpScrubber)(nil)
+
+func (s *regexpScrubber) Scrub(data []byte) []byte {
+	seen := map[string][]byte{}
+
+	return s.re.ReplaceAllFunc(data, func(m []byte) []byte {
+		p, ok := seen[string(m)]
+		if !ok {
+			p = []byte(fmt.Sprintf("<%s-%d>", s.name, len(seen)+1))
+			seen[string(m)] = p
+		}
+
+		return p
+	})
+}
+
+// Scrub returns a copy of data with the Scrubbers of the Default *Golden
+// instance applied.
+//
+// This is a wrapper around calling Scrub() on the Default *Golden instance.
+func Scrub(data []byte) []byte {
+	return Default.Scrub(data)
+}
+
+// Scrub returns a copy of data with all Scrubbers applied in order. If no
+// Scrubbers are set, data is returned as is.
+//
+// Scrubbers are applied automatically when writing and comparing golden
+// files. Scrub is useful to compare data against the content returned by
+// Do(), Get() and friends.
+func (s *Golden) Scrub(data []byte) []byte {
+	for _, scrubber := range s.Scrubbers {
+		data = scrubber.Scrub(data)
+	}
+
+	return data
+}
@@ -0,0 +1,210 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		scrubber Scrubber
+		data     string
+		want     string
+	}{
+		{
+			name:     "timestamps",
+			scrubber: TimestampScrubber(),
+			data: `{"a":"2024-01-02T03:04:05Z",` +
+				`"b":"2024-01-02T03:04:05.123456+07:00",` +
+				`"c":"2024-01-02T03:04:05Z","d":"2024-01-02"}`,
+			want: `{"a":"<TIMESTAMP-1>","b":"<TIMESTAMP-2>",` +
+				`"c":"<TIMESTAMP-1>","d":"2024-01-02"}`,
+		},
+		{
+			name:     "uuids",
+			scrubber: UUIDScrubber(),
+			data: "id=6ba7b810-9dad-11d1-80b4-00c04fd430c8 " +
+				"parent=123E4567-E89B-12D3-A456-426614174000 " +
+				"self=6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			want: "id=<UUID-1> parent=<UUID-2> self=<UUID-1>",
+		},
+		{
+			name:     "hex ids",
+			scrubber: HexIDScrubber(8),
+			data:     "commit 3f2a9c1d0e5b, port 8080, trace deadbeefcafe",
+			want:     "commit <HEXID-1>, port 8080, trace <HEXID-2>",
+		},
+		{
+			name:     "hex ids with invalid min length",
+			scrubber: HexIDScrubber(0),
+			data:     "a 1 ff",
+			want:     "<HEXID-1> <HEXID-2> <HEXID-3>",
+		},
+		{
+			name: "regexp",
+			scrubber: RegexpScrubber(
+				regexp.MustCompile(`127\.0\.0\.1:\d+`), "ADDR",
+			),
+			data: "listening on 127.0.0.1:41234, dialing 127.0.0.1:5432",
+			want: "listening on <ADDR-1>, dialing <ADDR-2>",
+		},
+		{
+			name: "func",
+			scrubber: ScrubberFunc(func(data []byte) []byte {
+				return []byte(strings.ToUpper(string(data)))
+			}),
+			data: "hello",
+			want: "HELLO",
+		},
+		{
+			name:     "no matches",
+			scrubber: UUIDScrubber(),
+			data:     "hello world",
+			want:     "hello world",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.scrubber.Scrub([]byte(tt.data))
+
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestTempDirScrubber(t *testing.T) {
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	data := "wrote " + filepath.Join(dir1, "out.txt") + "\n" +
+		"wrote " + filepath.Join(dir2, "sub", "out.txt") + "\n" +
+		"read " + dir1 + "\n" +
+		"other " + filepath.Join(os.TempDir(), "foo") + "\n"
+
+	got := TempDirScrubber().Scrub([]byte(data))
+
+	assert.Equal(t,
+		"wrote "+filepath.Join("<TEMPDIR-1>", "out.txt")+"\n"+
+			"wrote "+filepath.Join("<TEMPDIR-2>", "sub", "out.txt")+"\n"+
+			"read <TEMPDIR-1>\n"+
+			"other "+filepath.Join(os.TempDir(), "foo")+"\n",
+		string(got),
+	)
+}
+
+func TestGolden_Scrub(t *testing.T) {
+	g := &Golden{}
+	assert.Equal(t, []byte("id=1"), g.Scrub([]byte("id=1")))
+
+	g = New(WithScrubbers(
+		RegexpScrubber(regexp.MustCompile(`\d+`), "N"),
+		ScrubberFunc(func(data []byte) []byte {
+			return []byte(strings.ToLower(string(data)))
+		}),
+	))
+	assert.Equal(t, []byte("id=<n-1> <n-2> <n-1>"), g.Scrub([]byte("id=1 2 1")))
+}
+
+func TestGolden_Scrubbers(t *testing.T) {
+	t.Setenv("CI", "")
+
+	scrubbers := WithScrubbers(UUIDScrubber(), TimestampScrubber())
+	id := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		got        string
+		want       bool
+		wantFile   string
+		wantErrors []string
+	}{
+		{
+			name:     "update",
+			update:   true,
+			golden:   "old",
+			got:      "id=" + id + " at=2024-01-02T03:04:05Z",
+			want:     true,
+			wantFile: "id=<UUID-1> at=<TIMESTAMP-1>",
+		},
+		{
+			name:     "match",
+			golden:   "id=<UUID-1> at=<TIMESTAMP-1>",
+			got:      "id=" + id + " at=2030-12-31T23:59:59Z",
+			want:     true,
+			wantFile: "id=<UUID-1> at=<TIMESTAMP-1>",
+		},
+		{
+			name:     "mismatch",
+			golden:   "id=<UUID-1> id=<UUID-1>",
+			got:      "id=" + id + " id=123e4567-e89b-12d3-a456-426614174000",
+			want:     false,
+			wantFile: "id=<UUID-1> id=<UUID-1>",
+			wantErrors: []string{
+				"-id=<UUID-1> id=<UUID-1>\n",
+				"+id=<UUID-1> id=<UUID-2>\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+				WithActualFiles(true),
+				scrubbers,
+			)
+			ft := newFakeT("TestGolden_Scrubbers/" + tt.name)
+
+			f := g.File(ft)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte(tt.golden), 0o600)
+			require.NoError(t, err)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.Assert(ft, []byte(tt.got)) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			b, err := os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, string(b))
+
+			if !tt.want {
+				b, err = os.ReadFile(f + ".actual")
+				require.NoError(t, err)
+				assert.Equal(t, string(g.Scrub([]byte(tt.got))), string(b))
+			}
+		})
+	}
+}
+
+func TestGolden_Scrubbers_Set(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(WithDirname(t.TempDir()), WithScrubbers(TimestampScrubber()))
+	ft := newFakeT("TestGolden_Scrubbers_Set")
+
+	ft.run(func(ft TestingT) {
+		g.Set(ft, []byte("at 2024-01-02T03:04:05Z"))
+	})
+	require.False(t, ft.Failed(), ft.Output())
+
+	err := g.WriteP(ft, "p", []byte("at 2024-01-02T03:04:05Z"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("at <TIMESTAMP-1>"), g.Get(ft))
+	assert.Equal(t, []byte("at <TIMESTAMP-1>"), g.GetP(ft, "p"))
+}
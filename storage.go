@@ -0,0 +1,104 @@
+package golden
+
+import (
+	"fmt"
+	"os"
+)
+
+// Storage determines how golden files are stored on disk.
+type Storage int
+
+const (
+	// StorageFiles stores each golden file as a separate file.
+	StorageFiles Storage = iota
+
+	// StorageTxtar stores all named golden files of a test, as used by the
+	// "P" suffixed functions, as sections of a single txtar archive named
+	// after t.Name(), for example "testdata/TestFoo.txtar". Golden files
+	// without a name are still stored as separate files.
+	//
+	// The archive uses the format of the golang.org/x/tools/txtar package,
+	// where each section begins with a "-- name --" marker line, and ends
+	// with a newline. A newline is hence appended to data which does not end
+	// with one, both when written and when compared. Data containing marker
+	// lines cannot be stored.
+	StorageTxtar
+)
+
+// String returns the name of the storage.
+func (s Storage) String() string {
+	switch s {
+	case StorageFiles:
+		return "files"
+	case StorageTxtar:
+		return "txtar"
+	default:
+		return "unknown"
+	}
+}
+
+// txtar returns true if the golden file with the given name is stored in a
+// txtar archive.
+func (s *Golden) txtar(name string) bool {
+	return s.Storage == StorageTxtar && name != ""
+}
+
+// label returns the golden file path f, suffixed with "#" and the name of
+// the section if the golden file is stored in a txtar archive.
+func (s *Golden) label(f, name string) string {
+	if s.txtar(name) {
+		return f + "#" + name
+	}
+
+	return f
+}
+
+func (s *Golden) readTxtar(f, name string) ([]byte, error) {
+	a, err := readTxtarFile(f)
+	if os.IsNotExist(err) {
+		err = ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"golden: failed reading %s: %w", s.label(f, name), err,
+		)
+	}
+
+	b, ok := a.get(name)
+	if !ok {
+		return nil, fmt.Errorf(
+			"golden: failed reading %s: %w", s.label(f, name), ErrNotExist,
+		)
+	}
+
+	return b, nil
+}
+
+func (s *Golden) writeTxtar(f, name string, data []byte) error {
+	if !validTxtarName(name) {
+		return fmt.Errorf("golden: failed to write file: %w: %q",
+			errTxtarName, name,
+		)
+	}
+
+	if !validTxtarData(data) {
+		return fmt.Errorf("golden: failed to write file: %s: %w",
+			s.label(f, name), errTxtarMarker,
+		)
+	}
+
+	err := updateTxtarFile(f, s.DirMode, s.FileMode, func(a *txtarArchive) {
+		a.set(name, fixNL(data))
+	})
+	if err != nil {
+		return fmt.Errorf("golden: failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Golden) removeTxtar(f, name string) error {
+	return updateTxtarFile(f, s.DirMode, s.FileMode, func(a *txtarArchive) {
+		a.remove(name)
+	})
+}
@@ -0,0 +1,147 @@
+package golden
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_String(t *testing.T) {
+	assert.Equal(t, "files", StorageFiles.String())
+	assert.Equal(t, "txtar", StorageTxtar.String())
+	assert.Equal(t, "unknown", Storage(42).String())
+}
+
+func TestGolden_StorageTxtar(t *testing.T) {
+	t.Setenv("CI", "")
+
+	dir := t.TempDir()
+	g := New(
+		WithDirname(dir),
+		WithStorage(StorageTxtar),
+		WithUpdateFunc(func() bool { return true }),
+	)
+	ft := newFakeT("TestStorageTxtar/sub test")
+	archive := filepath.Join(dir, "TestStorageTxtar", "sub_test.txtar")
+
+	assert.Equal(t, archive, g.FileP(ft, "json"))
+	assert.Equal(t,
+		filepath.Join(dir, "TestStorageTxtar", "sub_test.golden"), g.File(ft),
+	)
+
+	g.SetP(ft, "json", []byte("{}\n"))
+	g.SetP(ft, "text", []byte("hello"))
+	g.SetP(ft, "json", []byte("[]\n"))
+	got := g.DoP(ft, "z", []byte("hello world"))
+	assert.Equal(t, []byte("hello world\n"), got)
+	g.Set(ft, []byte("unnamed"))
+	require.False(t, ft.Failed(), ft.Output())
+
+	b, err := os.ReadFile(archive)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"-- json --\n[]\n-- text --\nhello\n-- z --\nhello world\n",
+		string(b),
+	)
+
+	assert.Equal(t, []byte("[]\n"), g.GetP(ft, "json"))
+	assert.Equal(t, []byte("hello\n"), g.GetP(ft, "text"))
+	assert.Equal(t, []byte("unnamed"), g.Get(ft))
+
+	_, err = g.ReadP(ft, "missing")
+	assert.True(t, errors.Is(err, ErrNotExist))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.Contains(t, err.Error(), "sub_test.txtar#missing")
+}
+
+func TestGolden_StorageTxtar_Assert(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithStorage(StorageTxtar),
+		WithUpdateFunc(func() bool { return false }),
+		WithActualFiles(true),
+	)
+	ft := newFakeT("TestStorageTxtar_Assert")
+
+	f := g.FileP(ft, "a")
+	err := os.MkdirAll(filepath.Dir(f), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(f, []byte("-- a --\nfoo\n-- b --\nbar\n"), 0o600)
+	require.NoError(t, err)
+
+	assert.True(t, g.AssertP(ft, "a", []byte("foo")))
+	assert.True(t, g.AssertP(ft, "b", []byte("bar\n")))
+	assert.False(t, ft.Failed(), ft.Output())
+
+	assert.False(t, g.AssertP(ft, "a", []byte("baz")))
+	assert.False(t, g.AssertP(ft, "b", []byte("qux")))
+	assert.Contains(t, ft.Output(), "golden: "+f+"#a does not match:\n")
+	assert.Contains(t, ft.Output(), "--- "+f+"#b\n+++ actual\n")
+
+	b, err := os.ReadFile(f + ".actual")
+	require.NoError(t, err)
+	assert.Equal(t, "-- a --\nbaz\n-- b --\nqux\n", string(b))
+
+	assert.True(t, g.AssertP(ft, "a", []byte("foo")))
+	b, err = os.ReadFile(f + ".actual")
+	require.NoError(t, err)
+	assert.Equal(t, "-- b --\nqux\n", string(b))
+
+	assert.True(t, g.AssertP(ft, "b", []byte("bar")))
+	_, err = os.Stat(f + ".actual")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGolden_StorageTxtar_UpdateMissing(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithStorage(StorageTxtar),
+		WithUpdateFunc(func() bool { return true }),
+		WithUpdateMode(UpdateMissing),
+	)
+	ft := newFakeT("TestStorageTxtar_UpdateMissing")
+
+	err := g.WriteP(ft, "a", []byte("old\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("old\n"), g.DoP(ft, "a", []byte("new\n")))
+	assert.Equal(t, []byte("new\n"), g.DoP(ft, "b", []byte("new\n")))
+	assert.False(t, ft.Failed(), ft.Output())
+}
+
+func TestGolden_StorageTxtar_Errors(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(WithDirname(t.TempDir()), WithStorage(StorageTxtar))
+	ft := newFakeT("TestStorageTxtar_Errors")
+
+	err := g.WriteP(ft, "a", []byte("foo\n-- b --\nbar\n"))
+	assert.EqualError(t, err,
+		"golden: failed to write file: "+g.FileP(ft, "a")+"#a: "+
+			"data contains a txtar file marker line",
+	)
+
+	err = g.WriteP(ft, "a\nb", []byte("foo"))
+	assert.EqualError(t, err,
+		"golden: failed to write file: invalid txtar file name: \"a\\nb\"",
+	)
+
+	_, err = g.ReadP(ft, "a")
+	assert.True(t, errors.Is(err, ErrNotExist))
+	assert.EqualError(t, err,
+		"golden: failed reading "+g.FileP(ft, "a")+"#a: "+
+			"golden file does not exist",
+	)
+
+	t.Setenv("CI", "true")
+	err = g.WriteP(ft, "a", []byte("foo"))
+	assert.True(t, errors.Is(err, ErrUpdateInCI))
+}
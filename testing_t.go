@@ -7,3 +7,11 @@ type TestingT interface {
 	Logf(format string, args ...interface{})
 	Name() string
 }
+
+// CleanupT is an optional interface which TestingT instances may implement,
+// like *testing.T does, allowing work to be performed when the test finishes.
+// Writer(), WriterP(), Expect(), and VerifyGets all depend on it.
+type CleanupT interface {
+	TestingT
+	Cleanup(func())
+}
@@ -0,0 +1,119 @@
+package golden
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// fakeT is a TestingT implementation which records all failures and log
+// messages, allowing tests to verify failure behavior.
+type fakeT struct {
+	name string
+
+	mu     sync.Mutex
+	errors []string
+	fatals []string
+	logs   []string
+}
+
+var _ TestingT = (*fakeT)(nil)
+
+func newFakeT(name string) *fakeT {
+	return &fakeT{name: name}
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf records the failure and stops the calling goroutine, just like
+// *testing.T does. Hence functions which may call it should be executed with
+// run().
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.mu.Lock()
+	f.fatals = append(f.fatals, fmt.Sprintf(format, args...))
+	f.mu.Unlock()
+
+	runtime.Goexit()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Logf(format string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Name() string {
+	return f.name
+}
+
+// Failed returns true if Errorf() or Fatalf() has been called.
+func (f *fakeT) Failed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.errors) > 0 || len(f.fatals) > 0
+}
+
+// Output returns all recorded error and fatal messages joined by newlines.
+func (f *fakeT) Output() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	msgs := make([]string, 0, len(f.errors)+len(f.fatals))
+	msgs = append(msgs, f.errors...)
+	msgs = append(msgs, f.fatals...)
+
+	return strings.Join(msgs, "\n")
+}
+
+// run executes fn in a separate goroutine and waits for it to finish, allowing
+// Fatalf() to stop execution of fn without affecting the calling test.
+func (f *fakeT) run(fn func(t TestingT)) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(f)
+	}()
+	<-done
+}
+
+// fakeCleanupT is a fakeT which also implements Cleanup(), running all
+// registered functions in reverse order when cleanup() is called.
+type fakeCleanupT struct {
+	*fakeT
+
+	cleanups []func()
+}
+
+func newFakeCleanupT(name string) *fakeCleanupT {
+	return &fakeCleanupT{fakeT: newFakeT(name)}
+}
+
+func (f *fakeCleanupT) Cleanup(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// cleanup runs all registered cleanup functions with run(), in the reverse
+// order they were registered.
+func (f *fakeCleanupT) cleanup() {
+	f.mu.Lock()
+	fns := f.cleanups
+	f.cleanups = nil
+	f.mu.Unlock()
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		f.run(func(TestingT) { fns[i]() })
+	}
+}
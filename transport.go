@@ -0,0 +1,355 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+)
+
+// cassetteName is the name of the golden file holding the cassette of a test,
+// as used by Transport().
+const cassetteName = "cassette"
+
+// Transport returns a http.RoundTripper which records HTTP exchanges into a
+// cassette golden file, or replays them from it.
+//
+// This is a wrapper around calling Transport() on the Default *Golden
+// instance.
+func Transport(t TestingT, inner http.RoundTripper) http.RoundTripper {
+	t.Helper()
+
+	return Default.Transport(t, inner)
+}
+
+// Transport returns a http.RoundTripper for the given TestingT instance,
+// which records HTTP exchanges into a cassette golden file, or replays them
+// from it. The cassette is stored as JSON, using the usual golden file naming
+// with a name of "cassette", for example:
+//
+//	testdata/TestFetch/cassette.golden
+//
+// If UpdateTest() returns true, any existing cassette is replaced, and all
+// requests are sent with inner, or http.DefaultTransport if inner is nil.
+// Each request and its response are recorded, and the cassette is written
+// after every exchange. When UpdateMode() is UpdateMissing, exchanges are only
+// recorded if the cassette does not exist.
+//
+// Otherwise requests are never sent, and responses are instead replayed from
+// the cassette. Each recorded exchange is replayed at most once, for the first
+// request with the same method, URL, and body. Any other request fails the
+// test with t.Errorf(), and returns an error matching ErrUnexpectedRequest.
+//
+// Scrubbers are applied separately to the URL, body, and header values of
+// each recorded request and response, and to the URL and body of each request
+// before it is matched against the cassette during replay. For example, the
+// random port of a httptest.Server can be scrubbed with:
+//
+//	golden.RegexpScrubber(regexp.MustCompile(`127\.0\.0\.1:\d+`), "HOST")
+//
+// Headers listed in IgnoreHeaders are not recorded.
+func (s *Golden) Transport(
+	t TestingT,
+	inner http.RoundTripper,
+) http.RoundTripper {
+	t.Helper()
+
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+
+	tr := &transport{golden: s, t: t, inner: inner}
+
+	if s.UpdateTest(t, cassetteName) &&
+		(s.UpdateMode() != UpdateMissing || !s.exists(t, cassetteName)) {
+		tr.record = true
+		tr.cassette.Interactions = []*interaction{}
+
+		err := tr.save()
+		if err != nil {
+			s.fail(t, "%s", err.Error())
+		}
+
+		return tr
+	}
+
+	b, ok := s.get(t, cassetteName)
+	if !ok {
+		return tr
+	}
+
+	err := json.Unmarshal(b, &tr.cassette)
+	if err != nil {
+		s.fail(t, "golden: failed to unmarshal %s: %s",
+			s.file(t, cassetteName), err.Error(),
+		)
+	}
+
+	return tr
+}
+
+// cassette holds recorded HTTP exchanges.
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+// interaction is a single recorded HTTP exchange.
+type interaction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+
+	used bool
+}
+
+type cassetteRequest struct {
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Body   cassetteBody `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int          `json:"statusCode"`
+	Header     http.Header  `json:"header,omitempty"`
+	Body       cassetteBody `json:"body,omitempty"`
+}
+
+// cassetteBody is a HTTP body, which is stored as a JSON string if it is
+// valid UTF-8, and as an object holding base64 encoded data otherwise.
+type cassetteBody []byte
+
+type cassetteBinaryBody struct {
+	Base64 []byte `json:"base64"`
+}
+
+func (b cassetteBody) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return marshalCassetteJSON(string(b), "")
+	}
+
+	return marshalCassetteJSON(cassetteBinaryBody{Base64: b}, "")
+}
+
+func (b *cassetteBody) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var v cassetteBinaryBody
+		err := json.Unmarshal(data, &v)
+		*b = v.Base64
+
+		return err
+	}
+
+	var v string
+	err := json.Unmarshal(data, &v)
+	*b = []byte(v)
+
+	return err
+}
+
+type transport struct {
+	golden *Golden
+	t      TestingT
+	inner  http.RoundTripper
+	record bool
+
+	mu       sync.Mutex
+	cassette cassette
+}
+
+var _ http.RoundTripper = (*transport)(nil)
+
+// RoundTrip records or replays the given request. As required by
+// http.RoundTripper, req is not modified, other than consuming and closing its
+// body. When recording, a clone of req with a new body is sent instead.
+func (tr *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"golden: failed to read request body: %w", err,
+			)
+		}
+	}
+
+	if !tr.record {
+		return tr.replayExchange(req, body)
+	}
+
+	out := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	return tr.recordExchange(out, body)
+}
+
+// recordExchange sends req with the inner transport, and records the
+// exchange into the cassette.
+func (tr *transport) recordExchange(
+	req *http.Request,
+	body []byte,
+) (*http.Response, error) {
+	resp, err := tr.inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"golden: failed to read response body: %w", err,
+		)
+	}
+
+	s := tr.golden
+	header := http.Header{}
+	for name, values := range resp.Header {
+		if s.ignoredHeader(name) {
+			continue
+		}
+		for _, v := range values {
+			header.Add(name, string(s.Scrub([]byte(v))))
+		}
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.cassette.Interactions = append(tr.cassette.Interactions, &interaction{
+		Request: cassetteRequest{
+			Method: req.Method,
+			URL:    string(s.Scrub([]byte(req.URL.String()))),
+			Body:   s.Scrub(body),
+		},
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       s.Scrub(respBody),
+		},
+	})
+
+	err = tr.save()
+	if err != nil {
+		tr.t.Errorf("%s", err.Error())
+
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// replayExchange returns the response of the first unused recorded exchange
+// matching req.
+func (tr *transport) replayExchange(
+	req *http.Request,
+	body []byte,
+) (*http.Response, error) {
+	s := tr.golden
+	url := s.Scrub([]byte(req.URL.String()))
+	body = s.Scrub(body)
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	for _, i := range tr.cassette.Interactions {
+		if i.used || i.Request.Method != req.Method ||
+			i.Request.URL != string(url) ||
+			!bytes.Equal(i.Request.Body, body) {
+			continue
+		}
+
+		i.used = true
+
+		header := i.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+
+		return &http.Response{
+			Status: fmt.Sprintf("%d %s",
+				i.Response.StatusCode, http.StatusText(i.Response.StatusCode),
+			),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	f := s.label(s.file(tr.t, cassetteName), cassetteName)
+	tr.t.Errorf("golden: %s: %s: %s %s",
+		f, ErrUnexpectedRequest.Error(), req.Method, req.URL,
+	)
+
+	return nil, fmt.Errorf(
+		"golden: %w: %s %s", ErrUnexpectedRequest, req.Method, req.URL,
+	)
+}
+
+// save writes the cassette golden file. The caller must hold tr.mu, unless no
+// requests can be in flight.
+//
+// Scrubbers are not applied to the cassette as a whole, as each request and
+// response has already been scrubbed on its own. This keeps placeholders
+// numbered the same way as when requests are scrubbed during replay.
+func (tr *transport) save() error {
+	data, err := marshalCassetteJSON(&tr.cassette, "  ")
+	if err != nil {
+		return fmt.Errorf("golden: failed to marshal JSON: %w", err)
+	}
+
+	g := *tr.golden
+	g.Scrubbers = nil
+
+	return g.write(tr.t, cassetteName, data)
+}
+
+// marshalCassetteJSON marshals v as JSON indented with indent, without
+// escaping HTML characters, so placeholders like "<HOST-1>" stay readable.
+// A trailing newline is only included when indent is not empty.
+func marshalCassetteJSON(v interface{}, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if indent == "" {
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readBody reads and closes the body, and replaces it with a new reader of the
+// same data, so it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(b))
+
+	return b, nil
+}
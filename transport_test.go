@@ -0,0 +1,366 @@
+package golden
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			b, _ := io.ReadAll(r.Body)
+			w.Header().Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+			w.Header().Set("Content-Type", "text/plain")
+			switch r.URL.Path {
+			case "/binary":
+				_, _ = w.Write([]byte{0xff, 0x00, 0xfe})
+			case "/missing":
+				w.WriteHeader(http.StatusNotFound)
+			case "/item":
+				_, _ = w.Write([]byte("item " + r.URL.Query().Get("id")))
+			default:
+				_, _ = w.Write([]byte(r.Method + " " + r.URL.Path + " " +
+					string(b)))
+			}
+		},
+	))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func doRequest(
+	t *testing.T,
+	rt http.RoundTripper,
+	method, url, body string,
+) (*http.Response, string) {
+	t.Helper()
+
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return nil, err.Error()
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(b)
+}
+
+func TestGolden_Transport(t *testing.T) {
+	t.Setenv("CI", "")
+
+	srv, calls := newTestServer(t)
+	dir := t.TempDir()
+	update := true
+	g := New(
+		WithDirname(dir),
+		WithUpdateFunc(func() bool { return update }),
+		WithScrubbers(RegexpScrubber(
+			regexp.MustCompile(`127\.0\.0\.1:\d+`), "HOST",
+		)),
+	)
+	ft := newFakeT("TestTransport/record and replay")
+
+	f := g.FileP(ft, "cassette")
+	assert.Equal(t,
+		filepath.Join(dir, "TestTransport", "record_and_replay",
+			"cassette.golden",
+		),
+		f,
+	)
+
+	// Record.
+	rt := g.Transport(ft, nil)
+	resp, body := doRequest(t, rt, "GET", srv.URL+"/a", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "GET /a ", body)
+	_, body = doRequest(t, rt, "POST", srv.URL+"/a", "hello")
+	assert.Equal(t, "POST /a hello", body)
+	_, body = doRequest(t, rt, "GET", srv.URL+"/a", "")
+	assert.Equal(t, "GET /a ", body)
+	_, body = doRequest(t, rt, "GET", srv.URL+"/binary", "")
+	assert.Equal(t, "\xff\x00\xfe", body)
+	resp, _ = doRequest(t, rt, "GET", srv.URL+"/missing", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.False(t, ft.Failed(), ft.Output())
+	assert.Equal(t, 5, *calls)
+
+	b, err := os.ReadFile(f)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"url": "http://<HOST-1>/a"`)
+	assert.Contains(t, string(b), `"body": "hello"`)
+	assert.Contains(t, string(b), `"base64": "/wD+"`)
+	assert.NotContains(t, string(b), "Date")
+
+	// Replay, on a new server with a different port.
+	update = false
+	srv2, calls2 := newTestServer(t)
+	rt = g.Transport(ft, nil)
+
+	resp, body = doRequest(t, rt, "POST", srv2.URL+"/a", "hello")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "200 OK", resp.Status)
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "POST /a hello", body)
+	_, body = doRequest(t, rt, "GET", srv2.URL+"/a", "")
+	assert.Equal(t, "GET /a ", body)
+	_, body = doRequest(t, rt, "GET", srv2.URL+"/a", "")
+	assert.Equal(t, "GET /a ", body)
+	_, body = doRequest(t, rt, "GET", srv2.URL+"/binary", "")
+	assert.Equal(t, "\xff\x00\xfe", body)
+	resp, _ = doRequest(t, rt, "GET", srv2.URL+"/missing", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.False(t, ft.Failed(), ft.Output())
+	assert.Equal(t, 0, *calls2)
+
+	// Each exchange is only replayed once.
+	resp, msg := doRequest(t, rt, "GET", srv2.URL+"/a", "")
+	assert.Nil(t, resp)
+	assert.Contains(t, msg, "golden: unexpected request: GET "+srv2.URL+"/a")
+	assert.Equal(t,
+		[]string{
+			"golden: " + f + ": unexpected request: GET " + srv2.URL + "/a",
+		},
+		ft.errors,
+	)
+	assert.Equal(t, 0, *calls2)
+}
+
+func TestGolden_Transport_ScrubbedValues(t *testing.T) {
+	t.Setenv("CI", "")
+
+	srv, _ := newTestServer(t)
+	update := true
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return update }),
+		WithScrubbers(
+			RegexpScrubber(regexp.MustCompile(`127\.0\.0\.1:\d+`), "HOST"),
+			RegexpScrubber(regexp.MustCompile(`id=\d+`), "ID"),
+		),
+	)
+	ft := newFakeT("TestTransport_ScrubbedValues")
+
+	rt := g.Transport(ft, nil)
+	_, body := doRequest(t, rt, "GET", srv.URL+"/item?id=1", "")
+	assert.Equal(t, "item 1", body)
+	_, body = doRequest(t, rt, "GET", srv.URL+"/item?id=2", "")
+	assert.Equal(t, "item 2", body)
+	require.False(t, ft.Failed(), ft.Output())
+
+	b, err := os.ReadFile(g.FileP(ft, "cassette"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"url": "http://<HOST-1>/item?<ID-1>"`)
+	assert.NotContains(t, string(b), "<ID-2>")
+
+	update = false
+	rt = g.Transport(ft, nil)
+	_, body = doRequest(t, rt, "GET", srv.URL+"/item?id=7", "")
+	assert.Equal(t, "item 1", body)
+	_, body = doRequest(t, rt, "GET", srv.URL+"/item?id=8", "")
+	assert.Equal(t, "item 2", body)
+	assert.False(t, ft.Failed(), ft.Output())
+}
+
+func TestGolden_Transport_RequestNotModified(t *testing.T) {
+	t.Setenv("CI", "")
+
+	srv, _ := newTestServer(t)
+	update := true
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return update }),
+	)
+	ft := newFakeT("TestTransport_RequestNotModified")
+
+	for _, record := range []bool{true, false} {
+		update = record
+		rt := g.Transport(ft, nil)
+
+		body := &closeTracker{Reader: strings.NewReader("hello")}
+		req, err := http.NewRequest("POST", srv.URL+"/a", body)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "POST /a hello", string(b))
+		assert.Same(t, body, req.Body)
+		assert.True(t, body.closed)
+	}
+	assert.False(t, ft.Failed(), ft.Output())
+}
+
+// closeTracker is an io.ReadCloser which records if it has been closed.
+type closeTracker struct {
+	io.Reader
+
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+func TestGolden_Transport_UnexpectedRequest(t *testing.T) {
+	t.Setenv("CI", "")
+
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return false }),
+	)
+	ft := newFakeT("TestTransport_UnexpectedRequest")
+
+	err := g.WriteP(ft, "cassette", []byte(`{"interactions": []}`))
+	require.NoError(t, err)
+
+	rt := g.Transport(ft, nil)
+	req, err := http.NewRequest("GET", "http://example.com/", nil)
+	require.NoError(t, err)
+
+	resp, err := rt.RoundTrip(req)
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrUnexpectedRequest))
+	assert.Len(t, ft.errors, 1)
+}
+
+func TestGolden_Transport_UpdateMissing(t *testing.T) {
+	t.Setenv("CI", "")
+
+	srv, calls := newTestServer(t)
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+		WithUpdateMode(UpdateMissing),
+	)
+	ft := newFakeT("TestTransport_UpdateMissing")
+
+	_, body := doRequest(t, g.Transport(ft, nil), "GET", srv.URL+"/a", "")
+	assert.Equal(t, "GET /a ", body)
+	assert.Equal(t, 1, *calls)
+
+	_, body = doRequest(t, g.Transport(ft, nil), "GET", srv.URL+"/a", "")
+	assert.Equal(t, "GET /a ", body)
+	assert.Equal(t, 1, *calls)
+	assert.False(t, ft.Failed(), ft.Output())
+}
+
+func TestGolden_Transport_Errors(t *testing.T) {
+	t.Run("missing cassette", func(t *testing.T) {
+		t.Setenv("CI", "")
+
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return false }),
+		)
+		ft := newFakeT("TestTransport_Errors")
+
+		ft.run(func(ft TestingT) { g.Transport(ft, nil) })
+
+		require.Len(t, ft.fatals, 1)
+		assert.Contains(t, ft.fatals[0],
+			"cassette.golden: golden file does not exist",
+		)
+	})
+
+	t.Run("invalid cassette", func(t *testing.T) {
+		t.Setenv("CI", "")
+
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return false }),
+		)
+		ft := newFakeT("TestTransport_Errors")
+		err := g.WriteP(ft, "cassette", []byte(`[]`))
+		require.NoError(t, err)
+
+		ft.run(func(ft TestingT) { g.Transport(ft, nil) })
+
+		require.Len(t, ft.fatals, 1)
+		assert.Contains(t, ft.fatals[0], "golden: failed to unmarshal ")
+		assert.Contains(t, ft.fatals[0], "cassette.golden: json: ")
+	})
+
+	t.Run("update in CI", func(t *testing.T) {
+		t.Setenv("CI", "true")
+
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return true }),
+		)
+		ft := newFakeT("TestTransport_Errors")
+
+		ft.run(func(ft TestingT) { g.Transport(ft, nil) })
+
+		require.Len(t, ft.fatals, 1)
+		assert.Contains(t, ft.fatals[0], ErrUpdateInCI.Error())
+	})
+
+	t.Run("inner error", func(t *testing.T) {
+		t.Setenv("CI", "")
+
+		g := New(
+			WithDirname(t.TempDir()),
+			WithUpdateFunc(func() bool { return true }),
+		)
+		ft := newFakeT("TestTransport_Errors")
+		inner := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		req, err := http.NewRequest("GET", "http://example.com/", nil)
+		require.NoError(t, err)
+
+		_, err = g.Transport(ft, inner).RoundTrip(req)
+		assert.EqualError(t, err, "connection refused")
+		assert.False(t, ft.Failed(), ft.Output())
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+	ft := newFakeT("TestTransport")
+	srv, _ := newTestServer(t)
+
+	_, body := doRequest(t, Transport(ft, nil), "GET", srv.URL+"/a", "")
+	assert.Equal(t, "GET /a ", body)
+	assert.False(t, ft.Failed(), ft.Output())
+}
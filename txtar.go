@@ -0,0 +1,254 @@
+package golden
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// txtarSuffix is the filename suffix of txtar archives used by StorageTxtar.
+const txtarSuffix = ".txtar"
+
+var (
+	txtarMarkerStart = []byte("-- ")
+	txtarMarkerEnd   = []byte(" --")
+
+	// errTxtarMarker is returned when data cannot be stored in a txtar
+	// archive, as it contains a line which would be parsed as a file marker.
+	errTxtarMarker = errors.New("data contains a txtar file marker line")
+
+	// errTxtarName is returned when a name cannot be used as the name of a
+	// file within a txtar archive.
+	errTxtarName = errors.New("invalid txtar file name")
+
+	// txtarMu serializes all read-modify-write cycles of txtar archives.
+	txtarMu sync.Mutex
+)
+
+// txtarArchive is a txtar archive, in the format used by the
+// golang.org/x/tools/txtar package. It consists of a comment, followed by zero
+// or more files, each introduced by a "-- name --" marker line.
+type txtarArchive struct {
+	comment []byte
+	files   []txtarFile
+}
+
+type txtarFile struct {
+	name string
+	data []byte
+}
+
+// parseTxtar parses data as a txtar archive. Parsing never fails, as any data
+// is a valid txtar archive.
+func parseTxtar(data []byte) *txtarArchive {
+	a := &txtarArchive{}
+
+	var name string
+	a.comment, name, data = findTxtarMarker(data)
+	for name != "" {
+		f := txtarFile{name: name}
+		f.data, name, data = findTxtarMarker(data)
+		a.files = append(a.files, f)
+	}
+
+	return a
+}
+
+// findTxtarMarker finds the next file marker line in data, returning the data
+// before it, the name of the file, and the data after it. If there is no
+// marker, all of data is returned as before, with an empty name.
+func findTxtarMarker(data []byte) (before []byte, name string, after []byte) {
+	var i int
+	for {
+		if name, after = txtarMarker(data[i:]); name != "" {
+			return data[:i], name, after
+		}
+
+		j := bytes.IndexByte(data[i:], '\n')
+		if j < 0 {
+			return data, "", nil
+		}
+		i += j + 1
+	}
+}
+
+// txtarMarker returns the name of the file, and the data following the marker
+// line, if data begins with a file marker line.
+func txtarMarker(data []byte) (name string, after []byte) {
+	if !bytes.HasPrefix(data, txtarMarkerStart) {
+		return "", nil
+	}
+
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line, after = data[:i], data[i+1:]
+	}
+
+	if !bytes.HasSuffix(line, txtarMarkerEnd) ||
+		len(line) < len(txtarMarkerStart)+len(txtarMarkerEnd) {
+		return "", nil
+	}
+
+	name = strings.TrimSpace(string(
+		line[len(txtarMarkerStart) : len(line)-len(txtarMarkerEnd)],
+	))
+
+	return name, after
+}
+
+// format returns the serialized txtar archive.
+func (a *txtarArchive) format() []byte {
+	var buf bytes.Buffer
+	buf.Write(fixNL(a.comment))
+
+	for _, f := range a.files {
+		buf.WriteString("-- " + f.name + " --\n")
+		buf.Write(fixNL(f.data))
+	}
+
+	return buf.Bytes()
+}
+
+// get returns the data of the named file, and true if it exists.
+func (a *txtarArchive) get(name string) ([]byte, bool) {
+	for _, f := range a.files {
+		if f.name == name {
+			return f.data, true
+		}
+	}
+
+	return nil, false
+}
+
+// set replaces the data of the named file, or adds it if it does not exist.
+func (a *txtarArchive) set(name string, data []byte) {
+	for i, f := range a.files {
+		if f.name == name {
+			a.files[i].data = data
+
+			return
+		}
+	}
+
+	a.files = append(a.files, txtarFile{name: name, data: data})
+}
+
+// remove removes the named file, returning true if it existed.
+func (a *txtarArchive) remove(name string) bool {
+	for i, f := range a.files {
+		if f.name == name {
+			a.files = append(a.files[:i], a.files[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// fixNL returns data with a trailing newline added if it is not empty, and
+// does not already end with one.
+func fixNL(data []byte) []byte {
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return data
+	}
+
+	b := make([]byte, len(data)+1)
+	copy(b, data)
+	b[len(data)] = '\n'
+
+	return b
+}
+
+// validTxtarName returns true if name can be used as a file name within a
+// txtar archive.
+func validTxtarName(name string) bool {
+	return name != "" && name == strings.TrimSpace(name) &&
+		!strings.ContainsAny(name, "\r\n")
+}
+
+// validTxtarData returns true if data can be stored as a file within a txtar
+// archive, without any of its lines being parsed as a file marker.
+func validTxtarData(data []byte) bool {
+	_, name, _ := findTxtarMarker(data)
+
+	return name == ""
+}
+
+// readTxtarFile reads the txtar archive at path.
+func readTxtarFile(path string) (*txtarArchive, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTxtar(b), nil
+}
+
+// updateTxtarFile atomically applies fn to the txtar archive at path, creating
+// it if needed. If the archive is left without any files or comment, it is
+// removed.
+func updateTxtarFile(
+	path string,
+	dirMode, fileMode os.FileMode,
+	fn func(a *txtarArchive),
+) error {
+	txtarMu.Lock()
+	defer txtarMu.Unlock()
+
+	a, err := readTxtarFile(path)
+	if os.IsNotExist(err) {
+		a = &txtarArchive{}
+	} else if err != nil {
+		return err
+	}
+
+	fn(a)
+
+	if len(a.files) == 0 && len(a.comment) == 0 {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), dirMode)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, a.format(), fileMode)
+}
+
+// writeFileAtomic writes data to a temporary file next to path, and then
+// renames it to path, so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	return nil
+}
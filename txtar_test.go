@@ -0,0 +1,190 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTxtar(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want *txtarArchive
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: &txtarArchive{comment: []byte("")},
+		},
+		{
+			name: "comment only",
+			data: "some comment\n",
+			want: &txtarArchive{comment: []byte("some comment\n")},
+		},
+		{
+			name: "files",
+			data: "comment\n" +
+				"-- a.txt --\n" +
+				"hello\n" +
+				"--  b/c.txt  --\n" +
+				"\n" +
+				"world\n" +
+				"-- empty --\n",
+			want: &txtarArchive{
+				comment: []byte("comment\n"),
+				files: []txtarFile{
+					{name: "a.txt", data: []byte("hello\n")},
+					{name: "b/c.txt", data: []byte("\nworld\n")},
+					{name: "empty", data: []byte("")},
+				},
+			},
+		},
+		{
+			name: "marker without trailing newline",
+			data: "-- a --\nfoo\n-- b --",
+			want: &txtarArchive{
+				comment: []byte(""),
+				files: []txtarFile{
+					{name: "a", data: []byte("foo\n")},
+					{name: "b"},
+				},
+			},
+		},
+		{
+			name: "invalid markers",
+			data: "-- a --\n" +
+				"-- --\n" +
+				"--  --\n" +
+				"--a --\n" +
+				" -- b --\n" +
+				"-- c --\r\n",
+			want: &txtarArchive{
+				comment: []byte(""),
+				files: []txtarFile{
+					{
+						name: "a",
+						data: []byte("-- --\n--  --\n--a --\n" +
+							" -- b --\n-- c --\r\n"),
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTxtar([]byte(tt.data))
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTxtarArchive_format(t *testing.T) {
+	a := &txtarArchive{
+		comment: []byte("comment"),
+		files: []txtarFile{
+			{name: "a.txt", data: []byte("hello")},
+			{name: "b.txt", data: []byte("world\n")},
+			{name: "empty", data: nil},
+		},
+	}
+
+	got := a.format()
+
+	assert.Equal(t,
+		"comment\n-- a.txt --\nhello\n-- b.txt --\nworld\n-- empty --\n",
+		string(got),
+	)
+	assert.Equal(t,
+		&txtarArchive{
+			comment: []byte("comment\n"),
+			files: []txtarFile{
+				{name: "a.txt", data: []byte("hello\n")},
+				{name: "b.txt", data: []byte("world\n")},
+				{name: "empty", data: []byte("")},
+			},
+		},
+		parseTxtar(got),
+	)
+}
+
+func TestTxtarArchive_getSetRemove(t *testing.T) {
+	a := &txtarArchive{}
+
+	_, ok := a.get("a")
+	assert.False(t, ok)
+
+	a.set("a", []byte("1"))
+	a.set("b", []byte("2"))
+	a.set("a", []byte("3"))
+
+	b, ok := a.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("3"), b)
+	assert.Equal(t, "-- a --\n3\n-- b --\n2\n", string(a.format()))
+
+	assert.True(t, a.remove("a"))
+	assert.False(t, a.remove("a"))
+	assert.Equal(t, "-- b --\n2\n", string(a.format()))
+}
+
+func TestValidTxtarName(t *testing.T) {
+	assert.True(t, validTxtarName("foo"))
+	assert.True(t, validTxtarName("foo bar/baz.json"))
+	assert.False(t, validTxtarName(""))
+	assert.False(t, validTxtarName(" foo"))
+	assert.False(t, validTxtarName("foo "))
+	assert.False(t, validTxtarName("foo\nbar"))
+	assert.False(t, validTxtarName("foo\rbar"))
+}
+
+func TestValidTxtarData(t *testing.T) {
+	assert.True(t, validTxtarData([]byte("")))
+	assert.True(t, validTxtarData([]byte("foo\n-- bar\n")))
+	assert.False(t, validTxtarData([]byte("-- foo --\n")))
+	assert.False(t, validTxtarData([]byte("foo\n-- bar --\nbaz")))
+}
+
+func TestUpdateTxtarFile(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "sub", "TestFoo.txtar")
+
+	err := updateTxtarFile(f, 0o755, 0o644, func(a *txtarArchive) {
+		a.set("a", []byte("1\n"))
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"b", "c", "d", "e"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			err := updateTxtarFile(f, 0o755, 0o644, func(a *txtarArchive) {
+				a.set(name, []byte(name+"\n"))
+			})
+			assert.NoError(t, err)
+		}(name)
+	}
+	wg.Wait()
+
+	a, err := readTxtarFile(f)
+	require.NoError(t, err)
+	assert.Len(t, a.files, 5)
+
+	entries, err := os.ReadDir(filepath.Dir(f))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files must be removed")
+
+	err = updateTxtarFile(f, 0o755, 0o644, func(a *txtarArchive) {
+		a.files = nil
+	})
+	require.NoError(t, err)
+
+	_, err = os.Stat(f)
+	assert.True(t, os.IsNotExist(err))
+}
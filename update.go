@@ -1,26 +1,176 @@
 package golden
 
 import (
+	"fmt"
 	"os"
+	"regexp"
 	"strings"
 )
 
 var truthyStrings = []string{"1", "y", "t", "yes", "on", "true"}
 
+// missingString is the GOLDEN_UPDATE environment variable value which enables
+// updates with UpdateMissing mode.
+const missingString = "missing"
+
 type UpdateFunc func() bool
 
 // EnvUpdateFunc checks if the GOLDEN_UPDATE environment variable is set to
-// one of "1", "y", "t", "yes", "on", or "true".
+// one of "1", "y", "t", "yes", "on", "true", or "missing".
 //
 // This is also the default UpdateFunc used to determine the return value of
 // Update().
 func EnvUpdateFunc() bool {
-	env := os.Getenv("GOLDEN_UPDATE")
+	return isUpdateString(os.Getenv("GOLDEN_UPDATE"))
+}
+
+// AnyUpdateFunc returns an UpdateFunc which returns true if any of the given
+// UpdateFunc values return true. Nil values are ignored.
+func AnyUpdateFunc(funcs ...UpdateFunc) UpdateFunc {
+	return func() bool {
+		for _, f := range funcs {
+			if f != nil && f() {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// isUpdateString returns true if s is a truthy string, or "missing".
+func isUpdateString(s string) bool {
+	if strings.EqualFold(s, missingString) {
+		return true
+	}
+
+	return isTruthyString(s)
+}
+
+func isTruthyString(s string) bool {
 	for _, v := range truthyStrings {
-		if strings.EqualFold(env, v) {
+		if strings.EqualFold(s, v) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// UpdateTestFunc determines if the golden file belonging to the given TestingT
+// instance and optional golden file name should be updated. The name is empty
+// for golden files which are not specifically named via the "P" suffixed
+// functions.
+type UpdateTestFunc func(t TestingT, name string) bool
+
+// AdaptUpdateFunc returns an UpdateTestFunc which ignores its arguments, and
+// simply returns the result of calling the given UpdateFunc.
+func AdaptUpdateFunc(f UpdateFunc) UpdateTestFunc {
+	return func(TestingT, string) bool {
+		return f()
+	}
+}
+
+// EnvUpdateRunFunc checks if the golden file belonging to the given TestingT
+// instance and optional name matches the regular expression in the
+// GOLDEN_UPDATE_RUN environment variable. The regular expression is matched
+// against t.Name(), with the name appended after a "/" separator when not
+// empty. For example "TestFoo/.*json" matches named golden file "json" in
+// sub-tests of TestFoo.
+//
+// Returns true if GOLDEN_UPDATE_RUN is not set or is empty. If the regular
+// expression is invalid, the test is failed by calling t.Fatalf(), and false
+// is returned.
+func EnvUpdateRunFunc(t TestingT, name string) bool {
+	t.Helper()
+
+	ok, err := envUpdateRun(t, name)
+	if err != nil {
+		t.Fatalf("%s", err.Error())
+
+		return false
+	}
+
+	return ok
+}
+
+func envUpdateRun(t TestingT, name string) (bool, error) {
+	return matchUpdateRun(
+		t, "GOLDEN_UPDATE_RUN", os.Getenv("GOLDEN_UPDATE_RUN"), name,
+	)
+}
+
+// matchUpdateRun checks if t.Name() combined with the optional name matches
+// the regular expression pattern. The source describes where the pattern came
+// from, and is used in the error returned if the pattern is invalid.
+func matchUpdateRun(
+	t TestingT,
+	source, pattern, name string,
+) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, fmt.Errorf("golden: invalid %s: %w", source, err)
+	}
+
+	path := t.Name()
+	if name != "" {
+		path += "/" + name
+	}
+
+	return re.MatchString(path), nil
+}
+
+// UpdateMode determines which golden files are written when golden files are
+// being updated.
+type UpdateMode int
+
+const (
+	// UpdateAll writes all golden files, replacing the content of any existing
+	// golden files.
+	UpdateAll UpdateMode = iota
+
+	// UpdateMissing only writes golden files which do not exist yet. Existing
+	// golden files are left untouched and are compared against as usual.
+	UpdateMissing
+)
+
+// String returns the name of the update mode.
+func (m UpdateMode) String() string {
+	switch m {
+	case UpdateAll:
+		return "all"
+	case UpdateMissing:
+		return missingString
+	default:
+		return "unknown"
+	}
+}
+
+type UpdateModeFunc func() UpdateMode
+
+// EnvUpdateModeFunc returns UpdateMissing if the GOLDEN_UPDATE environment
+// variable is set to "missing", and UpdateAll otherwise.
+//
+// This is also the default UpdateModeFunc used to determine the return value
+// of UpdateMode().
+func EnvUpdateModeFunc() UpdateMode {
+	if strings.EqualFold(os.Getenv("GOLDEN_UPDATE"), missingString) {
+		return UpdateMissing
+	}
+
+	return UpdateAll
+}
+
+type CIFunc func() bool
+
+// EnvCIFunc checks if the CI environment variable is set to one of "1", "y",
+// "t", "yes", "on", or "true", as is conventionally done by most CI systems.
+//
+// This is also the default CIFunc used to determine the return value of CI().
+func EnvCIFunc() bool {
+	return isTruthyString(os.Getenv("CI"))
+}
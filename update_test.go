@@ -85,6 +85,11 @@ var envUpdateFuncTestCases = []struct {
 		env:  map[string]string{"GOLDEN_UPDATE": "false"},
 		want: false,
 	},
+	{
+		name: "GOLDEN_UPDATE set to missing",
+		env:  map[string]string{"GOLDEN_UPDATE": "missing"},
+		want: true,
+	},
 	{
 		name: "GOLDEN_UPDATE set to foobarnopebbq",
 		env:  map[string]string{"GOLDEN_UPDATE": "foobarnopebbq"},
@@ -116,6 +121,11 @@ var envUpdateFuncTestCases = []struct {
 		env:  map[string]string{"GOLDEN_UPDATE": "TrUe"},
 		want: true,
 	},
+	{
+		name: "GOLDEN_UPDATE set to MISSING (uppercase)",
+		env:  map[string]string{"GOLDEN_UPDATE": "MISSING"},
+		want: true,
+	},
 }
 
 func TestEnvUpdateFunc(t *testing.T) {
@@ -131,3 +141,253 @@ func TestEnvUpdateFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestAnyUpdateFunc(t *testing.T) {
+	yes := func() bool { return true }
+	no := func() bool { return false }
+
+	tests := []struct {
+		name  string
+		funcs []UpdateFunc
+		want  bool
+	}{
+		{name: "none", funcs: nil, want: false},
+		{name: "nil", funcs: []UpdateFunc{nil}, want: false},
+		{name: "false", funcs: []UpdateFunc{no}, want: false},
+		{name: "true", funcs: []UpdateFunc{yes}, want: true},
+		{name: "false and false", funcs: []UpdateFunc{no, no}, want: false},
+		{name: "false and true", funcs: []UpdateFunc{no, yes}, want: true},
+		{name: "true and false", funcs: []UpdateFunc{yes, no}, want: true},
+		{name: "nil and true", funcs: []UpdateFunc{nil, yes}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AnyUpdateFunc(tt.funcs...)()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAdaptUpdateFunc(t *testing.T) {
+	for _, want := range []bool{true, false} {
+		f := AdaptUpdateFunc(func() bool { return want })
+
+		assert.Equal(t, want, f(newFakeT("TestFoo"), ""))
+		assert.Equal(t, want, f(newFakeT("TestFoo"), "json"))
+	}
+}
+
+func TestEnvUpdateRunFunc(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		testName  string
+		named     string
+		want      bool
+		wantFatal string
+	}{
+		{
+			name:     "GOLDEN_UPDATE_RUN not set",
+			testName: "TestFoo",
+			want:     true,
+		},
+		{
+			name:     "GOLDEN_UPDATE_RUN set to empty string",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": ""},
+			testName: "TestFoo",
+			want:     true,
+		},
+		{
+			name:     "matching test name",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": "^TestFoo$"},
+			testName: "TestFoo",
+			want:     true,
+		},
+		{
+			name:     "non-matching test name",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": "^TestFoo$"},
+			testName: "TestBar",
+			want:     false,
+		},
+		{
+			name:     "matching sub-test name",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": "TestFoo/bar"},
+			testName: "TestFoo/bar_baz",
+			want:     true,
+		},
+		{
+			name:     "matching name",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": "TestFoo/.*json"},
+			testName: "TestFoo/bar",
+			named:    "json",
+			want:     true,
+		},
+		{
+			name:     "non-matching name",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": "TestFoo/.*json"},
+			testName: "TestFoo/bar",
+			named:    "xml",
+			want:     false,
+		},
+		{
+			name:     "name without sub-test",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": "^TestFoo/xml$"},
+			testName: "TestFoo",
+			named:    "xml",
+			want:     true,
+		},
+		{
+			name:     "invalid regular expression",
+			env:      map[string]string{"GOLDEN_UPDATE_RUN": "Test(Foo"},
+			testName: "TestFoo",
+			wantFatal: "golden: invalid GOLDEN_UPDATE_RUN: error parsing " +
+				"regexp: missing closing ): `Test(Foo`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			ft := newFakeT(tt.testName)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = EnvUpdateRunFunc(ft, tt.named) })
+
+			assert.Equal(t, tt.want, got)
+			if tt.wantFatal != "" {
+				assert.Equal(t, []string{tt.wantFatal}, ft.fatals)
+			} else {
+				assert.False(t, ft.Failed())
+			}
+		})
+	}
+}
+
+var envUpdateModeFuncTestCases = []struct {
+	name string
+	env  map[string]string
+	want UpdateMode
+}{
+	{
+		name: "GOLDEN_UPDATE not set",
+		want: UpdateAll,
+	},
+	{
+		name: "GOLDEN_UPDATE set to empty string",
+		env:  map[string]string{"GOLDEN_UPDATE": ""},
+		want: UpdateAll,
+	},
+	{
+		name: "GOLDEN_UPDATE set to 1",
+		env:  map[string]string{"GOLDEN_UPDATE": "1"},
+		want: UpdateAll,
+	},
+	{
+		name: "GOLDEN_UPDATE set to true",
+		env:  map[string]string{"GOLDEN_UPDATE": "true"},
+		want: UpdateAll,
+	},
+	{
+		name: "GOLDEN_UPDATE set to missing",
+		env:  map[string]string{"GOLDEN_UPDATE": "missing"},
+		want: UpdateMissing,
+	},
+	{
+		name: "GOLDEN_UPDATE set to Missing (mixed case)",
+		env:  map[string]string{"GOLDEN_UPDATE": "Missing"},
+		want: UpdateMissing,
+	},
+	{
+		name: "GOLDEN_UPDATE set to missing-ish",
+		env:  map[string]string{"GOLDEN_UPDATE": "missing-ish"},
+		want: UpdateAll,
+	},
+}
+
+func TestEnvUpdateModeFunc(t *testing.T) {
+	for _, tt := range envUpdateModeFuncTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got := EnvUpdateModeFunc()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUpdateMode_String(t *testing.T) {
+	tests := []struct {
+		mode UpdateMode
+		want string
+	}{
+		{mode: UpdateAll, want: "all"},
+		{mode: UpdateMissing, want: "missing"},
+		{mode: UpdateMode(99), want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mode.String())
+		})
+	}
+}
+
+var envCIFuncTestCases = []struct {
+	name string
+	env  map[string]string
+	want bool
+}{
+	{
+		name: "CI set to empty string",
+		env:  map[string]string{"CI": ""},
+		want: false,
+	},
+	{
+		name: "CI set to 0",
+		env:  map[string]string{"CI": "0"},
+		want: false,
+	},
+	{
+		name: "CI set to false",
+		env:  map[string]string{"CI": "false"},
+		want: false,
+	},
+	{
+		name: "CI set to 1",
+		env:  map[string]string{"CI": "1"},
+		want: true,
+	},
+	{
+		name: "CI set to true",
+		env:  map[string]string{"CI": "true"},
+		want: true,
+	},
+	{
+		name: "CI set to TRUE (uppercase)",
+		env:  map[string]string{"CI": "TRUE"},
+		want: true,
+	},
+	{
+		name: "CI set to yes",
+		env:  map[string]string{"CI": "yes"},
+		want: true,
+	},
+}
+
+func TestEnvCIFunc(t *testing.T) {
+	for _, tt := range envCIFuncTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got := EnvCIFunc()
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
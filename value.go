@@ -0,0 +1,123 @@
+package golden
+
+import (
+	"encoding/json"
+)
+
+// DoValue marshals got as JSON indented with two spaces, and then behaves
+// just like Do() with the resulting data. The content of the golden file is
+// then unmarshaled into a new value of type T and returned, allowing typed
+// values to be compared directly:
+//
+//	want := golden.DoValue(t, got)
+//	assert.Equal(t, want, got)
+//
+// If the golden file content cannot be unmarshaled, the test is failed
+// according to FailMode with an error naming the golden file, and the zero
+// value of T is returned.
+//
+// This is a wrapper around calling DoValueWith() with the Default *Golden
+// instance.
+func DoValue[T any](t TestingT, got T) T {
+	t.Helper()
+
+	return DoValueWith(Default, t, got)
+}
+
+// DoValueP marshals got with the codec registered for the file extension of
+// name, and then behaves just like the DoValue() method of *Golden with the
+// resulting data. The content of the golden file is then unmarshaled with the
+// same codec into a new value of type T and returned.
+//
+// If the golden file content cannot be unmarshaled, the test is failed
+// according to FailMode with an error naming the golden file, and the zero
+// value of T is returned.
+//
+// This is a wrapper around calling DoValuePWith() with the Default *Golden
+// instance.
+func DoValueP[T any](t TestingT, name string, got T) T {
+	t.Helper()
+
+	return DoValuePWith(Default, t, name, got)
+}
+
+// DoValueWith is the same as DoValue(), but uses the given *Golden instance
+// instead of Default. It is a function rather than a method of *Golden, as
+// methods cannot have type parameters:
+//
+//	g := golden.New(golden.WithScrubbers(golden.UUIDScrubber()))
+//	want := golden.DoValueWith(g, t, got)
+func DoValueWith[T any](g *Golden, t TestingT, got T) T {
+	t.Helper()
+
+	var want T
+	g.doTyped(t, "", got, &want)
+
+	return want
+}
+
+// DoValuePWith is the same as DoValueP(), but uses the given *Golden instance
+// instead of Default.
+func DoValuePWith[T any](g *Golden, t TestingT, name string, got T) T {
+	t.Helper()
+
+	var want T
+	if name == "" {
+		g.fail(t, "golden: name cannot be empty")
+
+		return want
+	}
+
+	g.doTyped(t, name, got, &want)
+
+	return want
+}
+
+// doTyped performs the update-or-read cycle for got, and unmarshals the golden
+// file content into want. When name is empty, got is stored as indented JSON
+// in the usual golden file, otherwise it is stored with the codec registered
+// for the file extension of name. Returns false if anything failed.
+func (s *Golden) doTyped(
+	t TestingT,
+	name string,
+	got interface{},
+	want interface{},
+) bool {
+	t.Helper()
+
+	var (
+		g         = s
+		unmarshal = json.Unmarshal
+		data      []byte
+		ok        bool
+	)
+
+	if name == "" {
+		data, ok = s.marshalJSON(t, got)
+	} else {
+		var codec Codec
+		g, codec, data, ok = s.marshalValue(t, name, got)
+		if ok {
+			unmarshal = codec.Unmarshal
+		}
+	}
+	if !ok {
+		return false
+	}
+
+	b, ok := g.do(t, name, data)
+	if !ok {
+		return false
+	}
+
+	err := unmarshal(b, want)
+	if err != nil {
+		s.fail(t, "golden: failed to unmarshal %s: %s",
+			g.file(t, name), err.Error(),
+		)
+
+		return false
+	}
+
+	return true
+}
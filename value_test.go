@@ -0,0 +1,209 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setDefault replaces the Default *Golden instance for the duration of the
+// test.
+func setDefault(t *testing.T, g *Golden) {
+	t.Helper()
+
+	orig := Default
+	t.Cleanup(func() { Default = orig })
+
+	Default = g
+}
+
+func TestDoValueWith(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		got        *jsonTestStruct
+		want       *jsonTestStruct
+		wantFatals []string
+	}{
+		{
+			name:   "read",
+			golden: `{"name": "foo", "tags": ["a"]}`,
+			got:    &jsonTestStruct{Name: "bar"},
+			want:   &jsonTestStruct{Name: "foo", Tags: []string{"a"}},
+		},
+		{
+			name:   "update",
+			update: true,
+			golden: `{"name": "foo"}`,
+			got:    &jsonTestStruct{Name: "bar", Count: 1.5},
+			want:   &jsonTestStruct{Name: "bar", Count: 1.5},
+		},
+		{
+			name:   "unmarshal error",
+			golden: `{"name": 1}`,
+			got:    &jsonTestStruct{Name: "bar"},
+			wantFatals: []string{
+				"golden: failed to unmarshal ",
+				filepath.Join("TestDoValue", "unmarshal_error.golden") +
+					": json: cannot unmarshal number",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestDoValue/" + tt.name)
+
+			f := g.File(ft)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte(tt.golden), 0o600)
+			require.NoError(t, err)
+
+			var got *jsonTestStruct
+			ft.run(func(ft TestingT) { got = DoValueWith(g, ft, tt.got) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantFatals) > 0, ft.Failed())
+			for _, msg := range tt.wantFatals {
+				assert.Contains(t, ft.Output(), msg)
+			}
+		})
+	}
+}
+
+func TestDoValueWith_MarshalError(t *testing.T) {
+	g := New(WithDirname(t.TempDir()))
+	ft := newFakeT("TestDoValue_MarshalError")
+
+	var got chan int
+	ft.run(func(ft TestingT) { got = DoValueWith(g, ft, make(chan int)) })
+
+	assert.Nil(t, got)
+	assert.Equal(t,
+		[]string{
+			"golden: failed to marshal JSON: json: unsupported type: chan int",
+		},
+		ft.fatals,
+	)
+}
+
+func TestDoValuePWith(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		file       string
+		update     bool
+		golden     string
+		got        xmlTestItem
+		want       xmlTestItem
+		wantFatals []string
+	}{
+		{
+			name:   "json read",
+			file:   "item.json",
+			golden: `{"ID": "1", "Name": "foo"}`,
+			got:    xmlTestItem{ID: "2", Name: "bar"},
+			want:   xmlTestItem{ID: "1", Name: "foo"},
+		},
+		{
+			name:   "xml read",
+			file:   "item.xml",
+			golden: `<item id="1"><name>foo</name></item>`,
+			got:    xmlTestItem{ID: "2", Name: "bar"},
+			want:   xmlTestItem{ID: "1", Name: "foo"},
+		},
+		{
+			name:   "xml update",
+			file:   "item.xml",
+			update: true,
+			golden: `<item id="1"><name>foo</name></item>`,
+			got:    xmlTestItem{ID: "2", Name: "bar"},
+			want:   xmlTestItem{ID: "2", Name: "bar"},
+		},
+		{
+			name:   "unmarshal error",
+			file:   "item.xml",
+			golden: `<item>`,
+			got:    xmlTestItem{ID: "2", Name: "bar"},
+			wantFatals: []string{
+				"golden: failed to unmarshal ",
+				filepath.Join("unmarshal_error", "item.xml") +
+					": XML syntax error on line 1: unexpected EOF",
+			},
+		},
+		{
+			name: "unknown extension",
+			file: "item.yaml",
+			got:  xmlTestItem{ID: "2", Name: "bar"},
+			wantFatals: []string{
+				`golden: no codec registered for ".yaml" file extension`,
+			},
+		},
+		{
+			name:       "empty name",
+			got:        xmlTestItem{ID: "2", Name: "bar"},
+			wantFatals: []string{"golden: name cannot be empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			g := New(
+				WithDirname(dir),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestDoValueP/" + tt.name)
+
+			if tt.golden != "" {
+				f := filepath.Join(
+					dir, "TestDoValueP", sanitizeFilename(tt.name), tt.file,
+				)
+				err := os.MkdirAll(filepath.Dir(f), 0o755)
+				require.NoError(t, err)
+				err = os.WriteFile(f, []byte(tt.golden), 0o600)
+				require.NoError(t, err)
+			}
+
+			var got xmlTestItem
+			ft.run(func(ft TestingT) {
+				got = DoValuePWith(g, ft, tt.file, tt.got)
+			})
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantFatals) > 0, ft.Failed())
+			for _, msg := range tt.wantFatals {
+				assert.Contains(t, ft.Output(), msg)
+			}
+		})
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+	ft := newFakeT("TestDoValue")
+	got := &jsonTestStruct{Name: "foo"}
+
+	assert.Equal(t, got, DoValue(ft, got))
+	assert.Equal(t, got, DoValueP(ft, "item.json", got))
+	assert.FileExists(t, File(ft))
+	assert.FileExists(t, filepath.Join(Default.Dirname, "TestDoValue",
+		"item.json",
+	))
+	assert.False(t, ft.Failed(), ft.Output())
+}
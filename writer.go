@@ -0,0 +1,100 @@
+package golden
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Writer returns an io.Writer whose content is compared against the golden
+// file of the given TestingT instance when the test finishes.
+//
+// This is a wrapper around calling Writer() on the Default *Golden instance.
+func Writer(t TestingT) io.Writer {
+	t.Helper()
+
+	return Default.Writer(t)
+}
+
+// WriterP returns an io.Writer whose content is compared against the named
+// golden file of the given TestingT instance when the test finishes.
+//
+// This is a wrapper around calling WriterP() on the Default *Golden instance.
+func WriterP(t TestingT, name string) io.Writer {
+	t.Helper()
+
+	return Default.WriterP(t, name)
+}
+
+// Writer returns an io.Writer which accumulates all data written to it. When
+// the test finishes, the accumulated data is handled just like Assert() does,
+// updating the golden file if needed, and failing the test on mismatch:
+//
+//	func TestRender(t *testing.T) {
+//		render(golden.Writer(t), &MyStruct{Foo: "Bar"})
+//	}
+//
+// The writer is safe for concurrent use. The comparison is registered with
+// t.Cleanup(), hence t must implement CleanupT, like *testing.T does.
+// Otherwise the test is failed according to FailMode.
+func (s *Golden) Writer(t TestingT) io.Writer {
+	t.Helper()
+
+	return s.writer(t, "")
+}
+
+// WriterP is like Writer(), but the accumulated data is handled just like
+// AssertP() does with the given name.
+func (s *Golden) WriterP(t TestingT, name string) io.Writer {
+	t.Helper()
+
+	if name == "" {
+		s.fail(t, "golden: name cannot be empty")
+
+		return io.Discard
+	}
+
+	return s.writer(t, name)
+}
+
+func (s *Golden) writer(t TestingT, name string) io.Writer {
+	t.Helper()
+
+	st := s.testState(t)
+	if st == nil {
+		return io.Discard
+	}
+
+	w := &goldenWriter{}
+	st.onFinish(func() {
+		t.Helper()
+
+		s.assert(t, name, w.Bytes())
+	})
+
+	return w
+}
+
+// goldenWriter is an io.Writer which accumulates all data written to it, and
+// is safe for concurrent use.
+type goldenWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+var _ io.Writer = (*goldenWriter)(nil)
+
+func (w *goldenWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+// Bytes returns a copy of all data written so far.
+func (w *goldenWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]byte(nil), w.buf.Bytes()...)
+}
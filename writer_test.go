@@ -0,0 +1,175 @@
+package golden
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGolden_Writer(t *testing.T) {
+	t.Setenv("CI", "")
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		write      []string
+		wantGolden string
+		wantErrors []string
+	}{
+		{
+			name:       "match",
+			golden:     "hello world\n",
+			write:      []string{"hello ", "world\n"},
+			wantGolden: "hello world\n",
+		},
+		{
+			name:       "update",
+			update:     true,
+			golden:     "old\n",
+			write:      []string{"new\n"},
+			wantGolden: "new\n",
+		},
+		{
+			name:       "mismatch",
+			golden:     "hello\n",
+			write:      []string{"world\n"},
+			wantGolden: "hello\n",
+			wantErrors: []string{
+				"mismatch.golden does not match:\n",
+				"@@ -1 +1 @@\n-hello\n+world\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeCleanupT("TestWriter/" + tt.name)
+
+			err := g.Write(ft, []byte(tt.golden))
+			require.NoError(t, err)
+
+			w := g.Writer(ft)
+			for _, s := range tt.write {
+				_, err = io.WriteString(w, s)
+				require.NoError(t, err)
+			}
+
+			assert.False(t, ft.Failed(), "compared before cleanup")
+			ft.cleanup()
+
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed(), ft.Output())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			b, err := os.ReadFile(g.File(ft))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantGolden, string(b))
+		})
+	}
+}
+
+func TestGolden_WriterP(t *testing.T) {
+	t.Setenv("CI", "")
+
+	dir := t.TempDir()
+	g := New(
+		WithDirname(dir),
+		WithUpdateFunc(func() bool { return true }),
+	)
+	ft := newFakeCleanupT("TestWriterP")
+
+	log := g.WriterP(ft, "log")
+	out := g.WriterP(ft, "out")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fmt.Fprint(log, "line\n")
+		}()
+	}
+	wg.Wait()
+	fmt.Fprint(out, "done\n")
+
+	ft.cleanup()
+	assert.False(t, ft.Failed(), ft.Output())
+
+	b, err := os.ReadFile(filepath.Join(dir, "TestWriterP", "log.golden"))
+	require.NoError(t, err)
+	assert.Len(t, b, 50)
+
+	b, err = os.ReadFile(filepath.Join(dir, "TestWriterP", "out.golden"))
+	require.NoError(t, err)
+	assert.Equal(t, "done\n", string(b))
+}
+
+func TestGolden_Writer_Errors(t *testing.T) {
+	g := New(WithDirname(t.TempDir()))
+
+	t.Run("no cleanup support", func(t *testing.T) {
+		ft := newFakeT("TestWriter_Errors")
+
+		var w io.Writer
+		ft.run(func(ft TestingT) { w = g.Writer(ft) })
+
+		assert.Nil(t, w)
+		assert.Equal(t,
+			[]string{"golden: *golden.fakeT does not support Cleanup()"},
+			ft.fatals,
+		)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		ft := newFakeCleanupT("TestWriter_Errors")
+
+		var w io.Writer
+		ft.run(func(TestingT) { w = g.WriterP(ft, "") })
+
+		assert.Nil(t, w)
+		assert.Equal(t, []string{"golden: name cannot be empty"}, ft.fatals)
+		assert.Empty(t, ft.cleanups)
+	})
+
+	t.Run("fail mode error", func(t *testing.T) {
+		g := g.With(WithFailMode(FailError))
+		ft := newFakeT("TestWriter_Errors")
+
+		w := g.Writer(ft)
+
+		assert.Equal(t, io.Discard, w)
+		assert.Equal(t,
+			[]string{"golden: *golden.fakeT does not support Cleanup()"},
+			ft.errors,
+		)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	t.Setenv("CI", "")
+
+	setDefault(t, New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return true }),
+	))
+
+	t.Run("sub", func(t *testing.T) {
+		fmt.Fprint(Writer(t), "hello\n")
+		fmt.Fprint(WriterP(t, "named"), "world\n")
+	})
+
+	ft := newFakeT("TestWriter/sub")
+	assert.Equal(t, []byte("hello\n"), Get(ft))
+	assert.Equal(t, []byte("world\n"), GetP(ft, "named"))
+}
@@ -0,0 +1,567 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DoXML marshals v as canonical XML, and then behaves just like Do() with the
+// resulting data. It returns the content of the golden file.
+//
+// This is a wrapper around calling DoXML() on the Default *Golden instance.
+func DoXML(t TestingT, v interface{}) []byte {
+	t.Helper()
+
+	return Default.DoXML(t, v)
+}
+
+// DoXMLP marshals v as canonical XML, and then behaves just like DoP() with
+// the resulting data. It returns the content of the golden file.
+//
+// This is a wrapper around calling DoXMLP() on the Default *Golden instance.
+func DoXMLP(t TestingT, name string, v interface{}) []byte {
+	t.Helper()
+
+	return Default.DoXMLP(t, name, v)
+}
+
+// AssertXML marshals v as canonical XML, and then behaves just like Assert()
+// with the resulting data, except that the golden file content is
+// canonicalized before being compared.
+//
+// This is a wrapper around calling AssertXML() on the Default *Golden
+// instance.
+func AssertXML(t TestingT, v interface{}) bool {
+	t.Helper()
+
+	return Default.AssertXML(t, v)
+}
+
+// AssertXMLP marshals v as canonical XML, and then behaves just like
+// AssertP() with the resulting data, except that the golden file content is
+// canonicalized before being compared.
+//
+// This is a wrapper around calling AssertXMLP() on the Default *Golden
+// instance.
+func AssertXMLP(t TestingT, name string, v interface{}) bool {
+	t.Helper()
+
+	return Default.AssertXMLP(t, name, v)
+}
+
+// DoXML marshals v with xml.Marshal() and canonicalizes the result, and then
+// behaves just like Do() with the resulting data. It returns the content of
+// the golden file.
+//
+// Canonical XML has attributes sorted by namespace and name, insignificant
+// whitespace removed, runs of whitespace within text collapsed into a single
+// space, comments and the XML declaration removed, namespace prefixes
+// normalized, and is indented with two spaces.
+func (s *Golden) DoXML(t TestingT, v interface{}) []byte {
+	t.Helper()
+
+	data, ok := s.marshalXML(t, v)
+	if !ok {
+		return nil
+	}
+
+	b, _ := s.do(t, "", data)
+
+	return b
+}
+
+// DoXMLP marshals v with xml.Marshal() and canonicalizes the result, and then
+// behaves just like DoP() with the resulting data. It returns the content of
+// the golden file.
+func (s *Golden) DoXMLP(t TestingT, name string, v interface{}) []byte {
+	t.Helper()
+
+	if name == "" {
+		s.fail(t, "golden: name cannot be empty")
+
+		return nil
+	}
+
+	data, ok := s.marshalXML(t, v)
+	if !ok {
+		return nil
+	}
+
+	b, _ := s.do(t, name, data)
+
+	return b
+}
+
+// AssertXML marshals v with xml.Marshal() and canonicalizes the result, and
+// then behaves just like Assert() with the resulting data.
+//
+// Instead of comparing byte-for-byte, the golden file content is parsed and
+// compared against the marshaled value element by element, so differences in
+// attribute order, whitespace and namespace prefixes are ignored. On mismatch,
+// each difference is reported by its element path, for example:
+//
+//	/items/item[2]/@id: want "1", got "2"
+func (s *Golden) AssertXML(t TestingT, v interface{}) bool {
+	t.Helper()
+
+	data, ok := s.marshalXML(t, v)
+	if !ok {
+		return false
+	}
+
+	return s.assertWith(t, "", data, compareXML)
+}
+
+// AssertXMLP marshals v with xml.Marshal() and canonicalizes the result, and
+// then behaves just like AssertP() with the resulting data. The golden file
+// content is compared just like AssertXML() does.
+func (s *Golden) AssertXMLP(t TestingT, name string, v interface{}) bool {
+	t.Helper()
+
+	if name == "" {
+		s.fail(t, "golden: name cannot be empty")
+
+		return false
+	}
+
+	data, ok := s.marshalXML(t, v)
+	if !ok {
+		return false
+	}
+
+	return s.assertWith(t, name, data, compareXML)
+}
+
+// XMLCodec is a Codec for XML golden files. Values are stored in canonical
+// form, and compared element by element, reporting differences by element
+// path.
+type XMLCodec struct{}
+
+var _ Codec = (*XMLCodec)(nil)
+
+// Marshal encodes v as XML.
+func (c *XMLCodec) Marshal(v interface{}) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+// Unmarshal decodes XML data into v.
+func (c *XMLCodec) Unmarshal(data []byte, v interface{}) error {
+	return xml.Unmarshal(data, v)
+}
+
+// Normalize returns the canonical form of XML data.
+func (c *XMLCodec) Normalize(data []byte) ([]byte, error) {
+	return canonicalXML(data)
+}
+
+// Diff compares want and got element by element, returning each difference by
+// its element path.
+func (c *XMLCodec) Diff(want, got []byte) string {
+	diff, _ := compareXML("", want, got)
+
+	return diff
+}
+
+func (s *Golden) marshalXML(t TestingT, v interface{}) ([]byte, bool) {
+	t.Helper()
+
+	data, err := marshalXML(v)
+	if err != nil {
+		s.fail(t, "golden: failed to marshal XML: %s", err.Error())
+
+		return nil, false
+	}
+
+	return data, true
+}
+
+// marshalXML marshals v as XML, and returns its canonical form.
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return canonicalXML(data)
+}
+
+// canonicalXML parses data as XML, and returns its canonical form.
+func canonicalXML(data []byte) ([]byte, error) {
+	doc, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, n := range doc.children {
+		writeXML(&buf, n, 0, "")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// compareXML is a compareFunc which parses and compares want and got as XML.
+func compareXML(_ string, want, got []byte) (string, bool) {
+	wantDoc, err := parseXML(want)
+	if err != nil {
+		return fmt.Sprintf("invalid XML in golden file: %s\n", err), false
+	}
+
+	gotDoc, err := parseXML(got)
+	if err != nil {
+		return fmt.Sprintf("invalid XML in actual: %s\n", err), false
+	}
+
+	c := &xmlComparer{}
+	c.compareChildren("", wantDoc, gotDoc)
+
+	if len(c.diffs) == 0 {
+		return "", true
+	}
+
+	return strings.Join(c.diffs, "\n") + "\n", false
+}
+
+type xmlKind int
+
+const (
+	xmlDocument xmlKind = iota
+	xmlElement
+	xmlText
+	xmlProcInst
+	xmlDirective
+)
+
+// xmlNode is a minimal XML document tree, holding only the parts of a
+// document which are relevant to its canonical form.
+type xmlNode struct {
+	kind     xmlKind
+	name     xml.Name
+	attrs    []xml.Attr
+	text     string
+	children []*xmlNode
+}
+
+func parseXML(data []byte) (*xmlNode, error) {
+	doc := &xmlNode{kind: xmlDocument}
+	stack := []*xmlNode{doc}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{kind: xmlElement, name: tok.Name}
+			for _, a := range tok.Attr {
+				if !isXMLNSAttr(a) {
+					n.attrs = append(n.attrs, a)
+				}
+			}
+			sort.Slice(n.attrs, func(i, j int) bool {
+				return xmlNameLess(n.attrs[i].Name, n.attrs[j].Name)
+			})
+
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			last := len(parent.children) - 1
+			if last >= 0 && parent.children[last].kind == xmlText {
+				parent.children[last].text += string(tok)
+			} else {
+				parent.children = append(parent.children,
+					&xmlNode{kind: xmlText, text: string(tok)},
+				)
+			}
+		case xml.ProcInst:
+			if tok.Target != "xml" {
+				parent.children = append(parent.children, &xmlNode{
+					kind: xmlProcInst,
+					name: xml.Name{Local: tok.Target},
+					text: strings.TrimSpace(string(tok.Inst)),
+				})
+			}
+		case xml.Directive:
+			parent.children = append(parent.children,
+				&xmlNode{kind: xmlDirective, text: string(tok)},
+			)
+		}
+	}
+
+	normalizeXMLText(doc)
+
+	return doc, nil
+}
+
+// normalizeXMLText collapses all whitespace within text nodes, and removes
+// text nodes which are left empty.
+func normalizeXMLText(n *xmlNode) {
+	children := n.children[:0]
+	for _, c := range n.children {
+		if c.kind == xmlText {
+			c.text = strings.Join(strings.Fields(c.text), " ")
+			if c.text == "" {
+				continue
+			}
+		}
+
+		normalizeXMLText(c)
+		children = append(children, c)
+	}
+
+	n.children = children
+}
+
+func isXMLNSAttr(a xml.Attr) bool {
+	return a.Name.Space == "xmlns" ||
+		(a.Name.Space == "" && a.Name.Local == "xmlns")
+}
+
+func xmlNameLess(a, b xml.Name) bool {
+	if a.Space != b.Space {
+		return a.Space < b.Space
+	}
+
+	return a.Local < b.Local
+}
+
+// xmlNamespaceURL is the namespace which the reserved "xml" prefix is bound
+// to.
+const xmlNamespaceURL = "http://www.w3.org/XML/1998/namespace"
+
+// writeXML writes the canonical form of node n to buf, indented to the given
+// depth. The defaultNS is the default namespace in scope of n.
+func writeXML(buf *bytes.Buffer, n *xmlNode, depth int, defaultNS string) {
+	indent := strings.Repeat("  ", depth)
+
+	switch n.kind {
+	case xmlText:
+		buf.WriteString(indent)
+		_ = xml.EscapeText(buf, []byte(n.text))
+		buf.WriteByte('\n')
+
+		return
+	case xmlProcInst:
+		fmt.Fprintf(buf, "%s<?%s %s?>\n", indent, n.name.Local, n.text)
+
+		return
+	case xmlDirective:
+		fmt.Fprintf(buf, "%s<!%s>\n", indent, n.text)
+
+		return
+	case xmlDocument, xmlElement:
+	}
+
+	buf.WriteString(indent + "<" + n.name.Local)
+
+	if n.name.Space != defaultNS {
+		defaultNS = n.name.Space
+		writeXMLAttr(buf, "xmlns", defaultNS)
+	}
+
+	prefixes := map[string]string{xmlNamespaceURL: "xml"}
+	for _, a := range n.attrs {
+		if _, ok := prefixes[a.Name.Space]; !ok && a.Name.Space != "" {
+			p := "ns" + strconv.Itoa(len(prefixes))
+			prefixes[a.Name.Space] = p
+			writeXMLAttr(buf, "xmlns:"+p, a.Name.Space)
+		}
+	}
+
+	for _, a := range n.attrs {
+		name := a.Name.Local
+		if a.Name.Space != "" {
+			name = prefixes[a.Name.Space] + ":" + name
+		}
+		writeXMLAttr(buf, name, a.Value)
+	}
+
+	buf.WriteByte('>')
+
+	switch {
+	case len(n.children) == 0:
+	case len(n.children) == 1 && n.children[0].kind == xmlText:
+		_ = xml.EscapeText(buf, []byte(n.children[0].text))
+	default:
+		buf.WriteByte('\n')
+		for _, c := range n.children {
+			writeXML(buf, c, depth+1, defaultNS)
+		}
+		buf.WriteString(indent)
+	}
+
+	buf.WriteString("</" + n.name.Local + ">\n")
+}
+
+func writeXMLAttr(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(" " + name + `="`)
+	_ = xml.EscapeText(buf, []byte(value))
+	buf.WriteByte('"')
+}
+
+// xmlComparer compares parsed XML documents, recording each difference by its
+// element path.
+type xmlComparer struct {
+	diffs []string
+}
+
+func (c *xmlComparer) add(path, format string, args ...interface{}) {
+	c.diffs = append(c.diffs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *xmlComparer) compareElement(path string, want, got *xmlNode) {
+	if want.name != got.name {
+		c.add(path, "want element <%s>, got <%s>",
+			xmlNameString(want.name), xmlNameString(got.name),
+		)
+
+		return
+	}
+
+	wantAttrs := xmlAttrMap(want.attrs)
+	gotAttrs := xmlAttrMap(got.attrs)
+	for _, k := range sortedStringKeys(wantAttrs, gotAttrs) {
+		p := path + "/@" + k
+		w, wok := wantAttrs[k]
+		g, gok := gotAttrs[k]
+
+		switch {
+		case !gok:
+			c.add(p, "missing, want %q", w)
+		case !wok:
+			c.add(p, "unexpected, got %q", g)
+		case w != g:
+			c.add(p, "want %q, got %q", w, g)
+		}
+	}
+
+	if w, g := xmlTextContent(want), xmlTextContent(got); w != g {
+		c.add(path+"/text()", "want %q, got %q", w, g)
+	}
+
+	c.compareChildren(path, want, got)
+}
+
+// compareChildren compares the child elements of want and got by position.
+func (c *xmlComparer) compareChildren(path string, want, got *xmlNode) {
+	wantElems := xmlElements(want)
+	gotElems := xmlElements(got)
+
+	for i := 0; i < len(wantElems) || i < len(gotElems); i++ {
+		switch {
+		case i >= len(gotElems):
+			c.add(xmlElementPath(path, wantElems, gotElems, i),
+				"missing, want <%s>", xmlNameString(wantElems[i].name),
+			)
+		case i >= len(wantElems):
+			c.add(xmlElementPath(path, gotElems, wantElems, i),
+				"unexpected, got <%s>", xmlNameString(gotElems[i].name),
+			)
+		default:
+			c.compareElement(
+				xmlElementPath(path, wantElems, gotElems, i),
+				wantElems[i], gotElems[i],
+			)
+		}
+	}
+}
+
+// xmlElementPath returns the path of elems[i] within path. A 1-based position
+// among siblings of the same name is included when either elems or others
+// contain more than one element of that name.
+func xmlElementPath(path string, elems, others []*xmlNode, i int) string {
+	name := elems[i].name
+	p := path + "/" + xmlNameString(name)
+
+	pos, count, otherCount := 0, 0, 0
+	for j, e := range elems {
+		if e.name == name {
+			count++
+			if j <= i {
+				pos++
+			}
+		}
+	}
+	for _, e := range others {
+		if e.name == name {
+			otherCount++
+		}
+	}
+
+	if count > 1 || otherCount > 1 {
+		p += "[" + strconv.Itoa(pos) + "]"
+	}
+
+	return p
+}
+
+func xmlElements(n *xmlNode) []*xmlNode {
+	var elems []*xmlNode
+	for _, c := range n.children {
+		if c.kind == xmlElement {
+			elems = append(elems, c)
+		}
+	}
+
+	return elems
+}
+
+func xmlTextContent(n *xmlNode) string {
+	var texts []string
+	for _, c := range n.children {
+		if c.kind == xmlText {
+			texts = append(texts, c.text)
+		}
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func xmlAttrMap(attrs []xml.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[xmlNameString(a.Name)] = a.Value
+	}
+
+	return m
+}
+
+func xmlNameString(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+
+	return "{" + n.Space + "}" + n.Local
+}
+
+func sortedStringKeys(maps ...map[string]string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,356 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type xmlTestItem struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name"`
+}
+
+type xmlTestStruct struct {
+	XMLName struct{}      `xml:"items"`
+	Kind    string        `xml:"kind,attr"`
+	Items   []xmlTestItem `xml:"item"`
+}
+
+func TestCanonicalXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: "",
+		},
+		{
+			name: "attribute order and whitespace",
+			data: `<?xml version="1.0" encoding="UTF-8"?>` +
+				"\n<root b=\"2\"   a=\"1\">\n\t<!-- comment -->\n" +
+				"  <name>  foo \n bar </name><empty/>\n</root>",
+			want: "<root a=\"1\" b=\"2\">\n" +
+				"  <name>foo bar</name>\n" +
+				"  <empty></empty>\n" +
+				"</root>\n",
+		},
+		{
+			name: "mixed content",
+			data: `<p>Hello <b>world</b>, bye &amp; "thanks"</p>`,
+			want: "<p>\n" +
+				"  Hello\n" +
+				"  <b>world</b>\n" +
+				"  , bye &amp; &#34;thanks&#34;\n" +
+				"</p>\n",
+		},
+		{
+			name: "namespaces",
+			data: `<x:root xmlns:x="urn:a" xmlns:y="urn:b" y:id="1" ` +
+				`xml:lang="en"><x:a/><b xmlns="urn:c"><c/></b><d/></x:root>`,
+			want: "<root xmlns=\"urn:a\" xmlns:ns1=\"urn:b\" " +
+				"xml:lang=\"en\" ns1:id=\"1\">\n" +
+				"  <a></a>\n" +
+				"  <b xmlns=\"urn:c\">\n" +
+				"    <c></c>\n" +
+				"  </b>\n" +
+				"  <d xmlns=\"\"></d>\n" +
+				"</root>\n",
+		},
+		{
+			name: "processing instruction and directive",
+			data: `<!DOCTYPE root><?style  a ?><root/>`,
+			want: "<!DOCTYPE root>\n" +
+				"<?style a?>\n" +
+				"<root></root>\n",
+		},
+		{
+			name: "invalid",
+			data: `<root><a></root>`,
+			wantErr: "XML syntax error on line 1: " +
+				"element <a> closed by </root>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalXML([]byte(tt.data))
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestCompareXML(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{
+			name: "equal",
+			want: `<a y="2" x="1"><b>foo</b></a>`,
+			got:  "<a x=\"1\" y=\"2\">\n  <b> foo </b>\n</a>",
+		},
+		{
+			name: "equal with different prefixes",
+			want: `<p:a xmlns:p="urn:x" xmlns:q="urn:y" q:id="1"/>`,
+			got:  `<a xmlns="urn:x" xmlns:z="urn:y" z:id="1"/>`,
+		},
+		{
+			name: "different namespaces",
+			want: `<a xmlns="urn:x"/>`,
+			got:  `<a xmlns="urn:y"/>`,
+			diff: "/{urn:x}a: want element <{urn:x}a>, got <{urn:y}a>\n",
+		},
+		{
+			name: "attributes",
+			want: `<a x="1" y="2"/>`,
+			got:  `<a x="3" z="4"/>`,
+			diff: "/a/@x: want \"1\", got \"3\"\n" +
+				"/a/@y: missing, want \"2\"\n" +
+				"/a/@z: unexpected, got \"4\"\n",
+		},
+		{
+			name: "text",
+			want: `<a><b>foo</b></a>`,
+			got:  `<a><b>bar</b></a>`,
+			diff: "/a/b/text(): want \"foo\", got \"bar\"\n",
+		},
+		{
+			name: "repeated elements",
+			want: `<a><b id="1"/><b id="2"/><c/></a>`,
+			got:  `<a><b id="1"/><b id="3"/></a>`,
+			diff: "/a/b[2]/@id: want \"2\", got \"3\"\n" +
+				"/a/c: missing, want <c>\n",
+		},
+		{
+			name: "unexpected element",
+			want: `<a><b/></a>`,
+			got:  `<a><b/><b/></a>`,
+			diff: "/a/b[2]: unexpected, got <b>\n",
+		},
+		{
+			name: "different element",
+			want: `<a><b/></a>`,
+			got:  `<a><c/></a>`,
+			diff: "/a/b: want element <b>, got <c>\n",
+		},
+		{
+			name: "invalid golden file",
+			want: `<a>`,
+			got:  `<a/>`,
+			diff: "invalid XML in golden file: " +
+				"XML syntax error on line 1: unexpected EOF\n",
+		},
+		{
+			name: "invalid actual",
+			want: `<a/>`,
+			got:  `<a>`,
+			diff: "invalid XML in actual: " +
+				"XML syntax error on line 1: unexpected EOF\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, ok := compareXML("want", []byte(tt.want), []byte(tt.got))
+
+			assert.Equal(t, tt.diff == "", ok)
+			assert.Equal(t, tt.diff, diff)
+		})
+	}
+}
+
+func TestDoXML(t *testing.T) {
+	t.Setenv("CI", "")
+
+	v := &xmlTestStruct{
+		Kind:  "list",
+		Items: []xmlTestItem{{ID: "1", Name: "foo"}},
+	}
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		v          interface{}
+		want       string
+		wantFatals []string
+	}{
+		{
+			name:   "read",
+			golden: `<items></items>`,
+			v:      v,
+			want:   `<items></items>`,
+		},
+		{
+			name:   "update",
+			update: true,
+			golden: `<items></items>`,
+			v:      v,
+			want: "<items kind=\"list\">\n" +
+				"  <item id=\"1\">\n" +
+				"    <name>foo</name>\n" +
+				"  </item>\n" +
+				"</items>\n",
+		},
+		{
+			name:   "marshal error",
+			update: true,
+			golden: "foo",
+			v:      make(chan int),
+			want:   "",
+			wantFatals: []string{
+				"golden: failed to marshal XML: " +
+					"xml: unsupported type: chan int",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestDoXML/" + tt.name)
+
+			for _, f := range []string{g.File(ft), g.FileP(ft, "xml")} {
+				err := os.MkdirAll(filepath.Dir(f), 0o755)
+				require.NoError(t, err)
+				err = os.WriteFile(f, []byte(tt.golden), 0o600)
+				require.NoError(t, err)
+			}
+
+			var got, gotP []byte
+			ft.run(func(ft TestingT) { got = g.DoXML(ft, tt.v) })
+			ft.run(func(ft TestingT) { gotP = g.DoXMLP(ft, "xml", tt.v) })
+
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.want, string(gotP))
+			assert.Equal(t, append(tt.wantFatals, tt.wantFatals...), ft.fatals)
+		})
+	}
+}
+
+func TestAssertXML(t *testing.T) {
+	t.Setenv("CI", "")
+
+	v := &xmlTestStruct{
+		Kind: "list",
+		Items: []xmlTestItem{
+			{ID: "1", Name: "foo"},
+			{ID: "2", Name: "bar"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		update     bool
+		golden     string
+		want       bool
+		wantFile   string
+		wantErrors []string
+	}{
+		{
+			name: "canonically equal",
+			golden: `<items kind="list"><item id="1"><name>foo</name></item>` +
+				`<item id="2"><name> bar </name></item></items>`,
+			want: true,
+			wantFile: `<items kind="list">` +
+				`<item id="1"><name>foo</name></item>` +
+				`<item id="2"><name> bar </name></item></items>`,
+		},
+		{
+			name: "mismatch",
+			golden: `<items kind="list"><item id="1"><name>foo</name></item>` +
+				`<item id="3"><name>baz</name></item></items>`,
+			want: false,
+			wantFile: `<items kind="list">` +
+				`<item id="1"><name>foo</name></item>` +
+				`<item id="3"><name>baz</name></item></items>`,
+			wantErrors: []string{
+				filepath.Join("TestAssertXML", "mismatch.golden") +
+					" does not match:\n" +
+					"/items/item[2]/@id: want \"3\", got \"2\"\n" +
+					"/items/item[2]/name/text(): want \"baz\", got \"bar\"\n",
+			},
+		},
+		{
+			name:   "mismatch with update",
+			update: true,
+			golden: `<items/>`,
+			want:   true,
+			wantFile: "<items kind=\"list\">\n" +
+				"  <item id=\"1\">\n" +
+				"    <name>foo</name>\n" +
+				"  </item>\n" +
+				"  <item id=\"2\">\n" +
+				"    <name>bar</name>\n" +
+				"  </item>\n" +
+				"</items>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(
+				WithDirname(t.TempDir()),
+				WithUpdateFunc(func() bool { return tt.update }),
+			)
+			ft := newFakeT("TestAssertXML/" + tt.name)
+
+			f := g.File(ft)
+			err := os.MkdirAll(filepath.Dir(f), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(f, []byte(tt.golden), 0o600)
+			require.NoError(t, err)
+
+			var got bool
+			ft.run(func(ft TestingT) { got = g.AssertXML(ft, v) })
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.wantErrors) > 0, ft.Failed())
+			for _, msg := range tt.wantErrors {
+				assert.Contains(t, ft.Output(), msg)
+			}
+
+			b, err := os.ReadFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, string(b))
+		})
+	}
+}
+
+func TestAssertXMLP(t *testing.T) {
+	g := New(
+		WithDirname(t.TempDir()),
+		WithUpdateFunc(func() bool { return false }),
+	)
+	ft := newFakeT("TestAssertXMLP")
+
+	f := g.FileP(ft, "resp")
+	err := os.MkdirAll(filepath.Dir(f), 0o755)
+	require.NoError(t, err)
+	err = os.WriteFile(f, []byte(`<items kind="a"></items>`), 0o600)
+	require.NoError(t, err)
+
+	assert.True(t, g.AssertXMLP(ft, "resp", &xmlTestStruct{Kind: "a"}))
+	assert.False(t, ft.Failed())
+
+	var got bool
+	ft.run(func(ft TestingT) { got = g.AssertXMLP(ft, "", "foo") })
+
+	assert.False(t, got)
+	assert.Equal(t, []string{"golden: name cannot be empty"}, ft.fatals)
+}